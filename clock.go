@@ -0,0 +1,63 @@
+package cron
+
+import (
+	"time"
+)
+
+// Clock is the source of time of Crontab. The default is the system clock.
+//
+// It can be replaced with WithClock to control the time in tests, see the
+// FakeClock in package crontest. Notice that the JobWrapper still measure the
+// durations with the system time, e.g. the timeout of WrapJobTimeout.
+type Clock interface {
+	// Now returns the current time.
+	Now() time.Time
+
+	// AtFunc waits until the time t and then calls fn. The fn is called in its
+	// own goroutine by the system clock, while a fake Clock may call it
+	// synchronously in the goroutine that advances the time, see FakeClock.Advance.
+	// The fn is called immediately if t is not after the current time.
+	// It returns a Timer that can be used to cancel the call using its Close method.
+	AtFunc(t time.Time, fn func()) Timer
+}
+
+// Timer represents the calls created by Clock.AtFunc or Driver.Schedule.
+type Timer interface {
+	// Close prevents the calls from firing. It does not wait for the fn
+	// if the call has been started.
+	Close()
+}
+
+// systemClock implements the Clock with system time.
+type systemClock struct{}
+
+// Now implements Clock.
+func (systemClock) Now() time.Time {
+	return time.Now()
+}
+
+// AtFunc implements Clock.
+func (systemClock) AtFunc(t time.Time, fn func()) Timer {
+	d := time.Until(t)
+	if d <= 0 {
+		go fn()
+		return firedTimer{}
+	}
+	return stdTimer{time.AfterFunc(d, fn)}
+}
+
+// stdTimer implements Timer with time.Timer.
+type stdTimer struct {
+	t *time.Timer
+}
+
+// Close implements Timer.
+func (t stdTimer) Close() {
+	t.t.Stop()
+}
+
+// firedTimer is the Timer of the call that has been started.
+type firedTimer struct{}
+
+// Close implements Timer.
+func (firedTimer) Close() {}
@@ -0,0 +1,105 @@
+package cron
+
+import (
+	"context"
+	"sync/atomic"
+	"time"
+)
+
+type contextKey int
+
+const (
+	runContextKey contextKey = iota
+)
+
+// runContext holds the information of a run, which is passed to the job by context.
+type runContext struct {
+	key     string
+	planned time.Time
+	next    time.Time // the planned time of the next run, zero if none.
+	clock   Clock
+	logger  Logger
+	emit    func(event Event)
+	start   func() // records the start of the run, called once by markStarted.
+	started int32  // set to 1 when the job is started.
+	skipped int32  // set to 1 by the JobWrapper that skips the run.
+}
+
+func (rc *runContext) isSkipped() bool {
+	return atomic.LoadInt32(&rc.skipped) == 1
+}
+
+// markStarted records the start of the run once, the retried runs are started
+// only once.
+func markStarted(ctx context.Context) {
+	if rc := getRunContext(ctx); rc != nil && atomic.CompareAndSwapInt32(&rc.started, 0, 1) {
+		rc.start()
+	}
+}
+
+// wrapJobStarted calls markStarted before the job is run. It is applied before
+// all JobWrapper, so that the runs skipped by them are not started.
+func wrapJobStarted(job Job) Job {
+	return JobFunc(func(ctx context.Context) error {
+		markStarted(ctx)
+		return job.Run(ctx)
+	})
+}
+
+// markSkipped marks the run as skipped by the JobWrapper.
+func markSkipped(ctx context.Context) {
+	if rc := getRunContext(ctx); rc != nil {
+		atomic.StoreInt32(&rc.skipped, 1)
+	}
+}
+
+// markDelayed reports the run is delayed by the JobWrapper for the duration.
+func markDelayed(ctx context.Context, d time.Duration) {
+	if rc := getRunContext(ctx); rc != nil {
+		rc.logger.Info("job run delayed", "key", rc.key, "planned", rc.planned, "delay", d)
+		rc.emit(Event{Type: EventRunDelayed, Key: rc.key, Time: rc.planned, Duration: d})
+	}
+}
+
+// markPanicked reports the panic of the run recovered by WrapJobRecover.
+func markPanicked(ctx context.Context, err *PanicError) {
+	if rc := getRunContext(ctx); rc != nil {
+		rc.emit(Event{Type: EventPanic, Key: rc.key, Time: rc.planned, Err: err})
+	}
+}
+
+func withRunContext(ctx context.Context, rc *runContext) context.Context {
+	return context.WithValue(ctx, runContextKey, rc)
+}
+
+func getRunContext(ctx context.Context) *runContext {
+	rc, _ := ctx.Value(runContextKey).(*runContext)
+	return rc
+}
+
+// JobKey returns the key of job from the context passed to Job.Run.
+// The ok is false if the job is not run by Crontab.
+func JobKey(ctx context.Context) (key string, ok bool) {
+	if rc := getRunContext(ctx); rc != nil {
+		return rc.key, true
+	}
+	return "", false
+}
+
+// PlannedTime returns the planned time of the run from the context passed to Job.Run.
+// The ok is false if the job is not run by Crontab.
+func PlannedTime(ctx context.Context) (t time.Time, ok bool) {
+	if rc := getRunContext(ctx); rc != nil {
+		return rc.planned, true
+	}
+	return time.Time{}, false
+}
+
+// loggerFromContext returns the Logger of Crontab from the context passed to Job.Run,
+// or the DefaultLogger if the job is not run by Crontab.
+func loggerFromContext(ctx context.Context) Logger {
+	if rc := getRunContext(ctx); rc != nil {
+		return rc.logger
+	}
+	return DefaultLogger
+}
@@ -2,78 +2,751 @@ package cron
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
-
-	"github.com/yu31/timewheel-go"
 )
 
 type Crontab struct {
 	mu       *sync.Mutex
-	tw       *timewheel.TimeWheel
-	jobs     map[string]*timewheel.Timer
+	driver   Driver
+	clock    Clock
+	jobs     map[string]*entry
 	jobChain JobChain
 	location *time.Location
+
+	// resumeMisfire handles the runs missed while a job is paused.
+	resumeMisfire MisfirePolicy
+
+	// store saves the persistent jobs, which are recreated by registry on Start.
+	store    Store
+	registry *Registry
+
+	// locker decides which instance runs a job, the lease expires after lockTTL.
+	locker    Locker
+	lockTTL   time.Duration
+	lockHooks LockHooks
+
+	// listeners receive the events of job lifecycle.
+	listeners []Listener
+
+	// logger used by Crontab and passed to the JobWrapper.
+	logger Logger
+
+	// groups limits the jobs running at once in each named group.
+	groups map[string]*Semaphore
+
+	// pool runs the fired runs if set, the overflow handles the full queue of pool,
+	// and the low priority runs waited longer than staleness are dropped.
+	pool      *workerPool
+	overflow  OverflowPolicy
+	staleness time.Duration
+
+	// runMu protects the fields below, which track the running jobs.
+	runMu   *sync.Mutex
+	running map[*entry]int
+	idle    chan struct{} // closed when no job is running.
+	stopped bool          // true after Shutdown called.
+
+	// halted is 1 until Start, and set to 1 by Stop and Shutdown to prevent the
+	// timers from firing.
+	halted int32
 }
 
 // New creates a Crontab.
 func New(opts ...Option) *Crontab {
 	cron := &Crontab{
 		mu:       new(sync.Mutex),
-		tw:       nil,
-		jobs:     make(map[string]*timewheel.Timer, 64),
+		driver:   nil,
+		clock:    nil,
+		jobs:     make(map[string]*entry, 64),
 		jobChain: nil,
 		location: time.Local,
+
+		resumeMisfire: MisfireSkip,
+		store:         nil,
+		registry:      nil,
+		locker:        nil,
+		lockTTL:       0,
+		lockHooks:     LockHooks{},
+		listeners:     nil,
+		logger:        DefaultLogger,
+		groups:        make(map[string]*Semaphore),
+		pool:          nil,
+		overflow:      OverflowBlock,
+		staleness:     0,
+
+		runMu:   new(sync.Mutex),
+		running: make(map[*entry]int),
+		idle:    nil,
+		stopped: false,
+		halted:  1,
 	}
 	for _, opt := range opts {
 		opt(cron)
 	}
-	cron.tw = timewheel.Default(timewheel.WithTimezone(cron.location))
+	if cron.clock == nil {
+		cron.clock = systemClock{}
+		if cron.driver == nil {
+			cron.driver = NewTimeWheelDriver()
+		}
+	}
+	if cron.driver == nil {
+		// The activations are fired by the Clock set with WithClock.
+		cron.driver = &clockDriver{clock: cron.clock}
+	}
+	if cron.pool != nil {
+		cron.pool.overflow = cron.overflow
+		cron.pool.staleness = cron.staleness
+		cron.pool.now = cron.clock.Now
+	}
 	return cron
 }
 
-// Start starts the crontab in its own goroutine
-func (cron *Crontab) Start() {
+// Start starts the crontab in its own goroutine.
+//
+// If a Store is set with WithStore, the persistent jobs are reloaded from the
+// Store before start. It returns error if any job cannot be reloaded, the other
+// jobs are still reloaded and started.
+func (cron *Crontab) Start() error {
+	var err error
+	if cron.store != nil {
+		err = cron.restore()
+	}
 	cron.mu.Lock()
-	cron.tw.Start()
+	atomic.StoreInt32(&cron.halted, 0)
+	if cron.pool != nil {
+		cron.pool.start()
+	}
+	cron.driver.Start()
+	for _, e := range cron.jobs {
+		if missed := e.missed; len(missed) != 0 {
+			e.missed = nil
+			cron.startCatchUp(e, missed)
+		}
+	}
 	cron.mu.Unlock()
+	return err
 }
 
 // Stop stops the crontab.
 //
 // Notice: By default, Stop does not wait for the running job completed.
-// You can use WrapJobWaitGroup to track the completion of jobs.
+// You can use Shutdown or WrapJobWaitGroup to track the completion of jobs.
 func (cron *Crontab) Stop() {
 	if cron == nil {
 		return
 	}
 	cron.mu.Lock()
-	cron.tw.Stop()
+	cron.halt()
+	cron.mu.Unlock()
+}
+
+// halt prevents the timers from firing. It must be called with cron.mu held.
+func (cron *Crontab) halt() {
+	atomic.StoreInt32(&cron.halted, 1)
+	cron.driver.Stop()
+	if cron.pool != nil {
+		cron.pool.stop()
+	}
+}
+
+// Shutdown stops the crontab gracefully. It prevents new runs of all jobs at once,
+// cancels the context passed to the running jobs and then waits for them to complete.
+//
+// If ctx is done before all running jobs completed, Shutdown returns a *ShutdownError
+// that contains the key of jobs still running.
+func (cron *Crontab) Shutdown(ctx context.Context) error {
+	cron.runMu.Lock()
+	cron.stopped = true
+	cron.runMu.Unlock()
+
+	cron.mu.Lock()
+	cron.halt()
+	for _, e := range cron.jobs {
+		e.close()
+		e.cancel()
+	}
 	cron.mu.Unlock()
+
+	for {
+		cron.runMu.Lock()
+		if len(cron.running) == 0 {
+			cron.runMu.Unlock()
+			return nil
+		}
+		idle := cron.idle
+		cron.runMu.Unlock()
+
+		select {
+		case <-idle:
+		case <-ctx.Done():
+			return &ShutdownError{Keys: cron.runningKeys(), Err: ctx.Err()}
+		}
+	}
 }
 
 // Submit adds or updates a job to the Crontab to be run on the given Schedule.
-// The old job with the key will be stopped and delete if exists.
-func (cron *Crontab) Submit(ctx context.Context, key string, job Job, schedule Schedule) {
+// The old job with the key will be stopped and delete if exists, its running
+// job is not canceled.
+//
+// It returns an error if the key is empty or the schedule is invalid, and
+// ErrShutdown if the Crontab has been shutdown.
+func (cron *Crontab) Submit(ctx context.Context, key string, job Job, schedule Schedule, opts ...SubmitOption) error {
 	if key == "" {
-		panic("cron: key cannot be empty")
+		return errors.New("cron: key cannot be empty")
+	}
+	if err := bindKey(schedule, key); err != nil {
+		return err
+	}
+	if err := cron.checkSchedule(schedule); err != nil {
+		return err
+	}
+	so := newSubmitOptions(opts)
+	if err := cron.checkGroup(so.group); err != nil {
+		return err
+	}
+	return cron.submit(ctx, key, job, schedule, so, nil)
+}
+
+// SubmitPersistent adds or updates a job like Submit, and saves it into the Store
+// set with WithStore, so that the job can be reloaded on Start after restarts.
+//
+// The job is created by the JobFactory registered with jobType and the payload;
+// the schedule must be a built-in UnixCron, Interval or Appoint. The record is
+// deleted from the Store once the schedule is exhausted, e.g. the Appoint has run.
+func (cron *Crontab) SubmitPersistent(ctx context.Context, key string, jobType string, payload []byte, schedule Schedule, opts ...SubmitOption) error {
+	if key == "" {
+		return errors.New("cron: key cannot be empty")
+	}
+	if cron.store == nil {
+		return errors.New("cron: no store is set")
+	}
+	if cron.isShutdown() {
+		// Not to save the job that never runs.
+		return ErrShutdown
+	}
+	if err := bindKey(schedule, key); err != nil {
+		return err
+	}
+	if err := cron.checkSchedule(schedule); err != nil {
+		return err
+	}
+	spec, err := NewScheduleSpec(schedule)
+	if err != nil {
+		return err
 	}
+	job, err := cron.registry.New(jobType, payload)
+	if err != nil {
+		return err
+	}
+	so := newSubmitOptions(opts)
+	if err = cron.checkGroup(so.group); err != nil {
+		return err
+	}
+	record := &Record{
+		Key:      key,
+		Schedule: spec,
+		JobType:  jobType,
+		Payload:  payload,
+		Misfire:  so.misfire,
+		Backlog:  so.backlog,
+		LastRun:  so.lastRun,
+		Group:    so.group,
+		Priority: so.priority,
+	}
+	if so.lastRun.IsZero() {
+		// The runs missed before the first run completed are handled from it on reload.
+		record.NextRun = firstActivation(schedule, cron.clock.Now().In(cron.location))
+	}
+	if err = cron.store.Save(record); err != nil {
+		return err
+	}
+	return cron.submit(ctx, key, job, schedule, so, record)
+}
+
+// submit adds or updates a job. The record is not nil if the job is persistent.
+// It returns ErrShutdown if the Crontab has been shutdown.
+func (cron *Crontab) submit(ctx context.Context, key string, job Job, schedule Schedule, so *submitOptions, record *Record) error {
+	job = cron.jobChain.Apply(wrapJobStarted(job))
+	if so.group != "" {
+		// The slot of group is acquired before all other JobWrapper.
+		job = WrapJobSemaphore(cron.groups[so.group])(job)
+	}
+	now := cron.clock.Now().In(cron.location)
+	e := newEntry(ctx, key, job, schedule, now)
+	e.record = record
+	e.priority = so.priority
+	if cron.locker != nil && unaligned(schedule) {
+		cron.logger.Warn("the planned times differ between instances, the runs are not deduplicated by locker", "key", key)
+	}
+
+	var missed []time.Time
+	var next time.Time
+
+	if !so.lastRun.IsZero() && so.lastRun.Before(now) {
+		// Computes the next run from the last run, the runs missed are handled by misfire policy.
+		missed, next = misfire(e, e.Next(so.lastRun.In(cron.location)), now, so.misfire, so.backlog)
+	} else if !so.nextRun.IsZero() {
+		// The reloaded job has never completed a run, the runs are missed since its first planned time.
+		missed, next = misfire(e, so.nextRun.In(cron.location), now, so.misfire, so.backlog)
+	} else {
+		next = firstActivation(e.schedule, now)
+	}
+
 	cron.mu.Lock()
+	if cron.isShutdown() {
+		cron.mu.Unlock()
+		e.cancel()
+		return ErrShutdown
+	}
 	// Stops old job if exists before.
-	if old, ok := cron.jobs[key]; ok {
-		old.Close()
+	old, replaced := cron.jobs[key]
+	if replaced {
+		cron.release(old)
+		if old.record != nil && record == nil {
+			cron.deleteRecord(key)
+		}
 	}
 	// Adds and start the new job.
-	cron.jobs[key] = cron.tw.ScheduleJob(ctx, schedule, cron.jobChain.Apply(job))
+	cron.jobs[key] = e
+	e.timer = cron.newTimer(e, next)
+	cron.startCatchUp(e, missed)
 	cron.mu.Unlock()
+
+	// The listeners are called without cron.mu held, so that they can read the jobs.
+	if replaced {
+		cron.emit(Event{Type: EventReplaced, Key: key})
+	} else {
+		cron.emit(Event{Type: EventSubmitted, Key: key})
+	}
+	cron.scheduled(e, next)
+	return nil
+}
+
+// isShutdown reports whether the Crontab has been shutdown.
+func (cron *Crontab) isShutdown() bool {
+	cron.runMu.Lock()
+	defer cron.runMu.Unlock()
+	return cron.stopped
+}
+
+// checkSchedule returns an error if the schedule is invalid or activates more
+// often than the Driver can fire.
+func (cron *Crontab) checkSchedule(schedule Schedule) error {
+	if err := validateSchedule(schedule); err != nil {
+		return err
+	}
+	return checkInterval(schedule, driverMinInterval(cron.driver))
+}
+
+// checkGroup returns an error if the group is not empty and not found.
+func (cron *Crontab) checkGroup(group string) error {
+	if _, ok := cron.groups[group]; group != "" && !ok {
+		return fmt.Errorf("cron: concurrency group %q not found", group)
+	}
+	return nil
+}
+
+// restore reloads the persistent jobs from the store.
+func (cron *Crontab) restore() error {
+	records, err := cron.store.Load()
+	if err != nil {
+		return err
+	}
+	var failed []string
+	for _, record := range records {
+		if err = cron.restoreRecord(record); err != nil {
+			cron.logger.Error("reload job from store failed", "key", record.Key, "error", err)
+			failed = append(failed, fmt.Sprintf("%s: %v", record.Key, err))
+		}
+	}
+	if len(failed) != 0 {
+		return fmt.Errorf("cron: reload jobs from store: %s", strings.Join(failed, "; "))
+	}
+	return nil
+}
+
+// restoreRecord recreates and submits the job of record.
+func (cron *Crontab) restoreRecord(record *Record) error {
+	if record.Schedule.Type == ScheduleTypeAppoint && !record.LastRun.Before(record.Schedule.Time) {
+		// The appointed run has been completed before restart.
+		cron.deleteRecord(record.Key)
+		return nil
+	}
+	schedule, err := record.Schedule.Schedule()
+	if err != nil {
+		return err
+	}
+	if err = bindKey(schedule, record.Key); err != nil {
+		return err
+	}
+	if err = cron.checkSchedule(schedule); err != nil {
+		return err
+	}
+	job, err := cron.registry.New(record.JobType, record.Payload)
+	if err != nil {
+		return err
+	}
+	so := newSubmitOptions([]SubmitOption{
+		WithMisfire(record.Misfire, record.LastRun),
+		WithMaxBacklog(record.Backlog),
+		WithGroup(record.Group),
+		WithPriority(record.Priority),
+	})
+	if record.LastRun.IsZero() {
+		so.nextRun = record.NextRun
+	}
+	if err = cron.checkGroup(so.group); err != nil {
+		return err
+	}
+	return cron.submit(context.Background(), record.Key, job, schedule, so, record)
 }
 
 // Remove delete and stop the job with specified id.
+// The running job is not canceled, see Shutdown.
 func (cron *Crontab) Remove(key string) {
 	cron.mu.Lock()
-	if old, ok := cron.jobs[key]; ok {
-		old.Close()
+	old, ok := cron.jobs[key]
+	if ok {
+		cron.release(old)
 		delete(cron.jobs, key)
+		if old.record != nil {
+			cron.deleteRecord(key)
+		}
+	}
+	cron.mu.Unlock()
+
+	if ok {
+		cron.emit(Event{Type: EventRemoved, Key: key})
+	}
+}
+
+// Pause stops the job with specified key from being run until Resume called.
+// The running job is not canceled.
+// It returns ErrJobNotFound if the job does not exist.
+func (cron *Crontab) Pause(key string) error {
+	cron.mu.Lock()
+	defer cron.mu.Unlock()
+	e, ok := cron.jobs[key]
+	if !ok {
+		return ErrJobNotFound
+	}
+	e.pause()
+	return nil
+}
+
+// Resume resumes the paused job with specified key. The next run is recomputed
+// from the Schedule of job; and the runs missed while paused are handled by the
+// MisfirePolicy set with WithResumeMisfire.
+// It returns ErrJobNotFound if the job does not exist.
+func (cron *Crontab) Resume(key string) error {
+	cron.mu.Lock()
+	e, ok := cron.jobs[key]
+	if !ok {
+		cron.mu.Unlock()
+		return ErrJobNotFound
+	}
+	planned, ok := e.resume()
+	if !ok {
+		cron.mu.Unlock()
+		return nil
+	}
+	now := cron.clock.Now().In(cron.location)
+	if planned.IsZero() {
+		planned = e.Next(now)
+	}
+	missed, next := misfire(e, planned, now, cron.resumeMisfire, 0)
+	e.timer = cron.newTimer(e, next)
+	cron.startCatchUp(e, missed)
+	cron.mu.Unlock()
+
+	cron.scheduled(e, next)
+	return nil
+}
+
+// Has reports whether the job with specified key exists.
+func (cron *Crontab) Has(key string) bool {
+	cron.mu.Lock()
+	_, ok := cron.jobs[key]
+	cron.mu.Unlock()
+	return ok
+}
+
+// Get returns the snapshot of the job with specified key.
+// The ok is false if the job does not exist.
+func (cron *Crontab) Get(key string) (info JobInfo, ok bool) {
+	cron.mu.Lock()
+	e, ok := cron.jobs[key]
+	cron.mu.Unlock()
+	if !ok {
+		return JobInfo{}, false
+	}
+	return e.info(), true
+}
+
+// Jobs returns the snapshot of all jobs, sorted by key.
+func (cron *Crontab) Jobs() []JobInfo {
+	cron.mu.Lock()
+	entries := make([]*entry, 0, len(cron.jobs))
+	for _, e := range cron.jobs {
+		entries = append(entries, e)
+	}
+	cron.mu.Unlock()
+
+	infos := make([]JobInfo, 0, len(entries))
+	for _, e := range entries {
+		infos = append(infos, e.info())
 	}
+	sort.Slice(infos, func(i, j int) bool { return infos[i].Key < infos[j].Key })
+	return infos
+}
+
+// PoolStats returns a snapshot of the worker pool set with WithWorkerPool.
+// It returns the zero PoolStats if no worker pool is set.
+func (cron *Crontab) PoolStats() PoolStats {
+	if cron.pool == nil {
+		return PoolStats{}
+	}
+	return cron.pool.stats()
+}
+
+// RunNow runs the job with specified key once immediately and returns its error.
+// The job is decorated by the same jobChain as scheduled runs, and its schedule
+// is not changed.
+//
+// It returns ErrJobNotFound if the job does not exist, ErrShutdown if the Crontab
+// has been shutdown, ErrJobSkipped if the run is skipped by the JobWrapper.
+func (cron *Crontab) RunNow(ctx context.Context, key string) error {
+	cron.mu.Lock()
+	e, ok := cron.jobs[key]
 	cron.mu.Unlock()
+	if !ok {
+		return ErrJobNotFound
+	}
+	now := cron.clock.Now().In(cron.location)
+	return cron.run(ctx, e, now, now)
+}
+
+// newTimer creates the timer to run the job of e on its schedule from next.
+// The caller emits the event of next by scheduled after cron.mu released.
+func (cron *Crontab) newTimer(e *entry, next time.Time) Timer {
+	e.setNext(next)
+	schedule := ScheduleFunc(func(prev time.Time) time.Time {
+		return e.Next(prev.In(cron.location))
+	})
+	return cron.driver.Schedule(schedule, next, func(planned time.Time, next time.Time) {
+		if atomic.LoadInt32(&cron.halted) == 1 {
+			return
+		}
+		cron.scheduled(e, next)
+		cron.dispatch(e, planned, planned)
+	})
+}
+
+// dispatch runs the job of e on the worker pool if set, otherwise in the
+// current goroutine. The due is the time the run is dispatched for, which
+// the lag of start is measured from.
+func (cron *Crontab) dispatch(e *entry, planned time.Time, due time.Time) {
+	if cron.pool == nil {
+		cron.fire(e, planned, due)
+		return
+	}
+	cron.pool.submit(e.priority, func() { cron.fire(e, planned, due) }, func() {
+		cron.logger.Warn("job run dropped by worker pool", "key", e.key, "planned", planned, "priority", e.priority)
+		cron.emit(Event{Type: EventRunSkipped, Key: e.key, Time: planned})
+	})
+}
+
+// scheduled emits the event of the next run of e.
+func (cron *Crontab) scheduled(e *entry, next time.Time) {
+	if next.IsZero() {
+		cron.emit(Event{Type: EventExhausted, Key: e.key})
+	} else {
+		cron.emit(Event{Type: EventScheduled, Key: e.key, Time: next})
+	}
+}
+
+// fire runs the job of e on its schedule.
+func (cron *Crontab) fire(e *entry, planned time.Time, due time.Time) {
+	if !cron.lock(e, planned) {
+		return
+	}
+	_ = cron.run(e.ctx, e, planned, due)
+	record := e.completed(planned)
+	if record == nil {
+		return
+	}
+	if record.NextRun.IsZero() {
+		// The exhausted job never runs again, deletes its record unless replaced.
+		cron.mu.Lock()
+		if cron.jobs[e.key] == e {
+			cron.deleteRecord(e.key)
+		}
+		cron.mu.Unlock()
+		return
+	}
+	if err := cron.store.Save(record); err != nil {
+		cron.logger.Error("save job to store failed", "key", e.key, "error", err)
+	}
+}
+
+// deleteRecord deletes the record of persistent job from store.
+func (cron *Crontab) deleteRecord(key string) {
+	if err := cron.store.Delete(key); err != nil {
+		cron.logger.Error("delete job from store failed", "key", key, "error", err)
+	}
+}
+
+// lock acquires the lease of the run with the locker.
+// It returns true if acquired or no locker is set.
+func (cron *Crontab) lock(e *entry, planned time.Time) bool {
+	if cron.locker == nil {
+		return true
+	}
+	acquired, err := cron.locker.Lock(e.ctx, e.key, planned, cron.lockTTL)
+	if err != nil {
+		cron.logger.Error("acquire lease failed", "key", e.key, "planned", planned, "error", err)
+	}
+	if err != nil || !acquired {
+		if cron.lockHooks.OnSkipped != nil {
+			cron.lockHooks.OnSkipped(e.key, planned, err)
+		}
+		return false
+	}
+	if cron.lockHooks.OnAcquired != nil {
+		cron.lockHooks.OnAcquired(e.key, planned)
+	}
+	return true
+}
+
+// startCatchUp dispatches the missed runs of e in background if the Crontab is
+// running, otherwise they are kept until Start. It must be called with cron.mu held.
+func (cron *Crontab) startCatchUp(e *entry, missed []time.Time) {
+	if len(missed) == 0 {
+		return
+	}
+	if atomic.LoadInt32(&cron.halted) == 1 {
+		e.missed = missed
+		return
+	}
+	cron.clock.AtFunc(cron.clock.Now(), func() { cron.catchUp(e, missed) })
+}
+
+// catchUp dispatches the missed runs of e in order. It gives up if the job is
+// removed or replaced, and keeps the rest until Start if the Crontab is stopped.
+func (cron *Crontab) catchUp(e *entry, missed []time.Time) {
+	for i, planned := range missed {
+		cron.mu.Lock()
+		current := cron.jobs[e.key] == e
+		halted := atomic.LoadInt32(&cron.halted) == 1
+		if current && halted {
+			e.missed = missed[i:]
+		}
+		cron.mu.Unlock()
+		if !current || halted {
+			return
+		}
+		// The lag of missed run is measured from the time it is caught up.
+		cron.dispatch(e, planned, cron.clock.Now())
+	}
+}
+
+// run executes the job of e with the planned time, the due is the time that the
+// lag of start is measured from.
+func (cron *Crontab) run(ctx context.Context, e *entry, planned time.Time, due time.Time) error {
+	if !cron.track(e) {
+		return ErrShutdown
+	}
+	defer cron.untrack(e)
+
+	rc := &runContext{key: e.key, planned: planned, next: e.getNext(), clock: cron.clock, logger: cron.logger, emit: cron.emit}
+	// The start is recorded only if the job is not skipped by the JobWrapper.
+	rc.start = func() {
+		e.started(planned)
+		cron.emit(Event{Type: EventRunStarted, Key: e.key, Time: planned, Duration: cron.clock.Now().Sub(due)})
+	}
+	start := cron.clock.Now()
+	err := e.job.Run(withRunContext(ctx, rc))
+	duration := cron.clock.Now().Sub(start)
+
+	if rc.isSkipped() {
+		cron.logger.Debug("job run skipped", "key", e.key, "planned", planned)
+		cron.emit(Event{Type: EventRunSkipped, Key: e.key, Time: planned, Duration: duration})
+		return ErrJobSkipped
+	}
+	cron.logger.Debug("job run finished", "key", e.key, "planned", planned, "duration", duration, "error", err)
+
+	e.finished(err)
+	cron.emit(Event{Type: EventRunFinished, Key: e.key, Time: planned, Duration: duration, Err: err})
+	return err
+}
+
+// emit sends the event to all listeners.
+func (cron *Crontab) emit(event Event) {
+	for _, listener := range cron.listeners {
+		listener.OnEvent(event)
+	}
+}
+
+// release stops the timer of the removed or replaced job of e. Its context is
+// canceled after the running jobs completed, so that they are not interrupted.
+func (cron *Crontab) release(e *entry) {
+	e.close()
+	cron.runMu.Lock()
+	e.closed = true
+	if cron.running[e] == 0 {
+		e.cancel()
+	}
+	cron.runMu.Unlock()
+}
+
+// track marks the job of e as running. It returns false if the Crontab is shutdown.
+func (cron *Crontab) track(e *entry) bool {
+	cron.runMu.Lock()
+	defer cron.runMu.Unlock()
+	if cron.stopped {
+		return false
+	}
+	if len(cron.running) == 0 {
+		cron.idle = make(chan struct{})
+	}
+	cron.running[e]++
+	return true
+}
+
+// untrack marks the job of e as completed.
+func (cron *Crontab) untrack(e *entry) {
+	cron.runMu.Lock()
+	if cron.running[e]--; cron.running[e] <= 0 {
+		delete(cron.running, e)
+		if e.closed {
+			e.cancel()
+		}
+	}
+	if len(cron.running) == 0 {
+		close(cron.idle)
+	}
+	cron.runMu.Unlock()
+}
+
+// runningKeys returns the sorted key of jobs that are running.
+func (cron *Crontab) runningKeys() []string {
+	cron.runMu.Lock()
+	seen := make(map[string]struct{}, len(cron.running))
+	keys := make([]string, 0, len(cron.running))
+	for e := range cron.running {
+		// The replaced job may be running together with the new one.
+		if _, ok := seen[e.key]; !ok {
+			seen[e.key] = struct{}{}
+			keys = append(keys, e.key)
+		}
+	}
+	cron.runMu.Unlock()
+	sort.Strings(keys)
+	return keys
 }
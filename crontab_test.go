@@ -1,7 +1,12 @@
 package cron
 
 import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/require"
 )
@@ -9,14 +14,14 @@ import (
 func TestCrontab_New(t *testing.T) {
 	cron := New()
 	require.NotNil(t, cron.mu)
-	require.NotNil(t, cron.tw)
+	require.NotNil(t, cron.driver)
 	require.NotNil(t, cron.jobs)
 }
 
 func TestCrontab_StartAndStop(t *testing.T) {
 	cron := New()
 	require.NotPanics(t, func() {
-		cron.Start()
+		require.Nil(t, cron.Start())
 	})
 	require.NotPanics(t, func() {
 		cron.Stop()
@@ -30,3 +35,450 @@ func TestCrontab_StartAndStop(t *testing.T) {
 		cron.Start()
 	})
 }
+
+func TestCrontab_Jobs(t *testing.T) {
+	cron := New()
+	require.Nil(t, cron.Start())
+	defer cron.Stop()
+
+	require.False(t, cron.Has("job1"))
+	_, ok := cron.Get("job1")
+	require.False(t, ok)
+
+	runC := make(chan struct{}, 16)
+	errJob := errors.New("job failed")
+	schedule := &Interval{Interval: time.Millisecond * 50}
+	require.Nil(t, cron.Submit(context.Background(), "job2", JobFunc(func(ctx context.Context) error {
+		return nil
+	}), &Appoint{Time: time.Now().Add(time.Hour)}))
+	require.Nil(t, cron.Submit(context.Background(), "job1", JobFunc(func(ctx context.Context) error {
+		runC <- struct{}{}
+		return errJob
+	}), schedule))
+
+	require.True(t, cron.Has("job1"))
+	info, ok := cron.Get("job1")
+	require.True(t, ok)
+	require.Equal(t, "job1", info.Key)
+	require.Equal(t, schedule, info.Schedule)
+	require.True(t, info.Prev.IsZero())
+	require.False(t, info.Next.IsZero())
+	require.False(t, info.Submitted.IsZero())
+	require.Equal(t, int64(0), info.Runs)
+
+	<-runC
+	<-runC
+	require.Eventually(t, func() bool {
+		info, _ = cron.Get("job1")
+		return info.Runs >= 2 && info.LastError != nil
+	}, time.Second, time.Millisecond*10)
+	require.Equal(t, errJob, info.LastError)
+	require.False(t, info.Prev.IsZero())
+	require.True(t, info.Next.After(info.Prev))
+
+	jobs := cron.Jobs()
+	require.Len(t, jobs, 2)
+	require.Equal(t, "job1", jobs[0].Key)
+	require.Equal(t, "job2", jobs[1].Key)
+
+	cron.Remove("job1")
+	require.False(t, cron.Has("job1"))
+	require.Len(t, cron.Jobs(), 1)
+}
+
+func TestCrontab_SubmitInvalid(t *testing.T) {
+	cron := New()
+	require.Nil(t, cron.Start())
+	defer cron.Stop()
+
+	job := JobFunc(func(ctx context.Context) error { return nil })
+	require.NotNil(t, cron.Submit(context.Background(), "", job, &Interval{Interval: time.Hour}))
+	require.NotNil(t, cron.Submit(context.Background(), "job1", job, nil))
+	require.NotNil(t, cron.Submit(context.Background(), "job1", job, &UnixCron{Express: "* * *"}))
+	require.NotNil(t, cron.Submit(context.Background(), "job1", job, &Interval{}))
+	require.NotNil(t, cron.Submit(context.Background(), "job1", job, &Appoint{}))
+	require.False(t, cron.Has("job1"))
+
+	require.Nil(t, cron.Submit(context.Background(), "job1", job, &UnixCron{Express: "* * * * *"}))
+	require.True(t, cron.Has("job1"))
+}
+
+func TestCrontab_Shutdown(t *testing.T) {
+	cron := New()
+	require.Nil(t, cron.Start())
+
+	startC := make(chan struct{})
+	var canceled int32
+	require.Nil(t, cron.Submit(context.Background(), "job1", JobFunc(func(ctx context.Context) error {
+		close(startC)
+		<-ctx.Done()
+		time.Sleep(time.Millisecond * 20)
+		atomic.StoreInt32(&canceled, 1)
+		return ctx.Err()
+	}), &Appoint{Time: time.Now()}))
+
+	<-startC
+	err := cron.Shutdown(context.Background())
+	require.Nil(t, err)
+	require.Equal(t, int32(1), atomic.LoadInt32(&canceled))
+
+	// No jobs are running.
+	require.Nil(t, cron.Shutdown(context.Background()))
+}
+
+func TestCrontab_RemoveRunning(t *testing.T) {
+	cron := New()
+	require.Nil(t, cron.Start())
+	defer cron.Stop()
+
+	for _, remove := range []func(){
+		func() { cron.Remove("job1") },
+		func() {
+			require.Nil(t, cron.Submit(context.Background(), "job1", JobFunc(func(ctx context.Context) error {
+				return nil
+			}), &Interval{Interval: time.Hour}))
+		},
+	} {
+		startC := make(chan context.Context)
+		releaseC := make(chan struct{})
+		require.Nil(t, cron.Submit(context.Background(), "job1", JobFunc(func(ctx context.Context) error {
+			startC <- ctx
+			<-releaseC
+			return nil
+		}), &Appoint{Time: time.Now().Add(time.Millisecond * 10)}))
+		ctx := <-startC
+
+		// The running job is not canceled by Remove or replaced.
+		remove()
+		time.Sleep(time.Millisecond * 20)
+		require.Nil(t, ctx.Err())
+
+		// The context is canceled after completed.
+		close(releaseC)
+		require.Eventually(t, func() bool { return ctx.Err() != nil }, time.Second, time.Millisecond*5)
+	}
+}
+
+func TestCrontab_ShutdownTimeout(t *testing.T) {
+	cron := New()
+	require.Nil(t, cron.Start())
+
+	startC := make(chan struct{})
+	doneC := make(chan struct{})
+	defer close(doneC)
+	require.Nil(t, cron.Submit(context.Background(), "job1", JobFunc(func(ctx context.Context) error {
+		close(startC)
+		<-doneC
+		return nil
+	}), &Appoint{Time: time.Now()}))
+
+	<-startC
+	ctx, cancel := context.WithTimeout(context.Background(), time.Millisecond*20)
+	defer cancel()
+	err := cron.Shutdown(ctx)
+	require.NotNil(t, err)
+
+	var shutdownErr *ShutdownError
+	require.True(t, errors.As(err, &shutdownErr))
+	require.Equal(t, []string{"job1"}, shutdownErr.Keys)
+	require.True(t, errors.Is(err, context.DeadlineExceeded))
+}
+
+func TestCrontab_PauseAndResume(t *testing.T) {
+	cron := New()
+	require.Nil(t, cron.Start())
+	defer cron.Stop()
+
+	require.Equal(t, ErrJobNotFound, cron.Pause("job1"))
+	require.Equal(t, ErrJobNotFound, cron.Resume("job1"))
+
+	var runs int32
+	require.Nil(t, cron.Submit(context.Background(), "job1", JobFunc(func(ctx context.Context) error {
+		atomic.AddInt32(&runs, 1)
+		return nil
+	}), &Interval{Interval: time.Millisecond * 20}))
+
+	require.Nil(t, cron.Pause("job1"))
+	require.Nil(t, cron.Pause("job1"))
+	info, _ := cron.Get("job1")
+	require.True(t, info.Paused)
+	require.True(t, info.Next.IsZero())
+
+	time.Sleep(time.Millisecond * 60)
+	require.Equal(t, int32(0), atomic.LoadInt32(&runs))
+
+	require.Nil(t, cron.Resume("job1"))
+	require.Nil(t, cron.Resume("job1"))
+	info, _ = cron.Get("job1")
+	require.False(t, info.Paused)
+	require.False(t, info.Next.IsZero())
+
+	require.Eventually(t, func() bool {
+		return atomic.LoadInt32(&runs) > 0
+	}, time.Second, time.Millisecond*10)
+}
+
+func TestCrontab_RunNow(t *testing.T) {
+	var wrapped int32
+	cron := New(WithJobWrapper(func(job Job) Job {
+		return JobFunc(func(ctx context.Context) error {
+			atomic.AddInt32(&wrapped, 1)
+			return job.Run(ctx)
+		})
+	}))
+	require.Nil(t, cron.Start())
+	defer cron.Stop()
+
+	require.Equal(t, ErrJobNotFound, cron.RunNow(context.Background(), "job1"))
+
+	errJob := errors.New("job failed")
+	require.Nil(t, cron.Submit(context.Background(), "job1", JobFunc(func(ctx context.Context) error {
+		return errJob
+	}), &Interval{Interval: time.Hour}))
+	before, _ := cron.Get("job1")
+
+	require.Equal(t, errJob, cron.RunNow(context.Background(), "job1"))
+	require.Equal(t, int32(1), atomic.LoadInt32(&wrapped))
+
+	after, _ := cron.Get("job1")
+	require.Equal(t, int64(1), after.Runs)
+	require.Equal(t, errJob, after.LastError)
+	require.Equal(t, before.Next, after.Next)
+
+	require.Nil(t, cron.Shutdown(context.Background()))
+	require.Equal(t, ErrShutdown, cron.RunNow(context.Background(), "job1"))
+
+	// No jobs are submitted after shutdown.
+	require.Equal(t, ErrShutdown, cron.Submit(context.Background(), "job2", JobFunc(func(ctx context.Context) error {
+		return nil
+	}), &Interval{Interval: time.Hour}))
+	require.False(t, cron.Has("job2"))
+}
+
+func TestCrontab_RunSkipped(t *testing.T) {
+	var mu sync.Mutex
+	var types []EventType
+	listener := ListenerFunc(func(event Event) {
+		if event.Type == EventRunStarted || event.Type == EventRunSkipped || event.Type == EventRunFinished {
+			mu.Lock()
+			types = append(types, event.Type)
+			mu.Unlock()
+		}
+	})
+	cron := New(WithListener(listener), WithJobWrapper(WrapJobSkipIfRunning()))
+	require.Nil(t, cron.Start())
+	defer cron.Stop()
+
+	startC := make(chan struct{})
+	releaseC := make(chan struct{})
+	require.Nil(t, cron.Submit(context.Background(), "job1", JobFunc(func(ctx context.Context) error {
+		close(startC)
+		<-releaseC
+		return nil
+	}), &Interval{Interval: time.Hour}))
+	doneC := make(chan struct{})
+	go func() {
+		_ = cron.RunNow(context.Background(), "job1")
+		close(doneC)
+	}()
+	<-startC
+	first, _ := cron.Get("job1")
+
+	// The skipped run is not recorded as started.
+	require.Equal(t, ErrJobSkipped, cron.RunNow(context.Background(), "job1"))
+	info, _ := cron.Get("job1")
+	require.Equal(t, int64(1), info.Runs)
+	require.Equal(t, first.Prev, info.Prev)
+	close(releaseC)
+	<-doneC
+
+	mu.Lock()
+	defer mu.Unlock()
+	require.Equal(t, []EventType{EventRunStarted, EventRunSkipped, EventRunFinished}, types)
+}
+
+func TestCrontab_SubmitMisfire(t *testing.T) {
+	now := time.Now()
+	lastRun := now.Add(-time.Hour*5 - time.Minute)
+	cases := []struct {
+		policy  MisfirePolicy
+		backlog int
+		planned []time.Time
+	}{
+		{MisfireSkip, 0, nil},
+		{MisfireRunOnce, 0, []time.Time{lastRun.Add(time.Hour * 5)}},
+		{MisfireRunAll, 0, []time.Time{
+			lastRun.Add(time.Hour), lastRun.Add(time.Hour * 2), lastRun.Add(time.Hour * 3),
+			lastRun.Add(time.Hour * 4), lastRun.Add(time.Hour * 5),
+		}},
+		{MisfireRunAll, 2, []time.Time{lastRun.Add(time.Hour), lastRun.Add(time.Hour * 2)}},
+	}
+	for _, c := range cases {
+		cron := New()
+		require.Nil(t, cron.Start())
+
+		var mu sync.Mutex
+		var planned []time.Time
+		require.Nil(t, cron.Submit(context.Background(), "job1", JobFunc(func(ctx context.Context) error {
+			p, ok := PlannedTime(ctx)
+			require.True(t, ok)
+			mu.Lock()
+			planned = append(planned, p)
+			mu.Unlock()
+			return nil
+		}), &Interval{Interval: time.Hour}, WithMisfire(c.policy, lastRun), WithMaxBacklog(c.backlog)))
+
+		time.Sleep(time.Millisecond * 50)
+		mu.Lock()
+		require.Equal(t, len(c.planned), len(planned))
+		for i := range c.planned {
+			require.True(t, c.planned[i].Equal(planned[i]))
+		}
+		mu.Unlock()
+
+		info, _ := cron.Get("job1")
+		require.True(t, info.Next.After(now))
+		cron.Stop()
+	}
+}
+
+func TestCrontab_CatchUp(t *testing.T) {
+	cron := New(WithWorkerPool(1, 0))
+	var runs int32
+	job := JobFunc(func(ctx context.Context) error {
+		atomic.AddInt32(&runs, 1)
+		return nil
+	})
+
+	// The missed runs are not caught up before Start, and limited by DefaultMaxBacklog.
+	lastRun := time.Now().Add(-time.Hour)
+	require.Nil(t, cron.Submit(context.Background(), "job1", job, &Interval{Interval: time.Second}, WithMisfire(MisfireRunAll, lastRun)))
+	time.Sleep(time.Millisecond * 50)
+	require.Equal(t, int32(0), atomic.LoadInt32(&runs))
+
+	require.Nil(t, cron.Start())
+	require.Eventually(t, func() bool {
+		return atomic.LoadInt32(&runs) == DefaultMaxBacklog
+	}, time.Second, time.Millisecond*10)
+	require.Equal(t, int64(DefaultMaxBacklog), cron.PoolStats().Dispatched)
+	cron.Stop()
+
+	// The missed runs are not caught up after Stop.
+	require.Nil(t, cron.Submit(context.Background(), "job2", job, &Interval{Interval: time.Minute}, WithMisfire(MisfireRunOnce, lastRun)))
+	time.Sleep(time.Millisecond * 50)
+	require.Equal(t, int32(DefaultMaxBacklog), atomic.LoadInt32(&runs))
+}
+
+func TestCrontab_Listener(t *testing.T) {
+	var mu sync.Mutex
+	var events []Event
+	listener := ListenerFunc(func(event Event) {
+		mu.Lock()
+		events = append(events, event)
+		mu.Unlock()
+	})
+	cron := New(WithListener(listener), WithJobWrapper(WrapJobRecover()))
+	require.Nil(t, cron.Start())
+	defer cron.Stop()
+
+	at := time.Now().Add(time.Millisecond * 10)
+	require.Nil(t, cron.Submit(context.Background(), "job1", JobFunc(func(ctx context.Context) error {
+		panic("oops")
+	}), &Appoint{Time: at}))
+
+	require.Eventually(t, func() bool {
+		mu.Lock()
+		defer mu.Unlock()
+		return len(events) == 6
+	}, time.Second, time.Millisecond*10)
+
+	require.Nil(t, cron.Submit(context.Background(), "job1", JobFunc(func(ctx context.Context) error {
+		return nil
+	}), &Interval{Interval: time.Hour}))
+	cron.Remove("job1")
+
+	mu.Lock()
+	defer mu.Unlock()
+	types := make([]EventType, 0, len(events))
+	for _, event := range events {
+		require.Equal(t, "job1", event.Key)
+		types = append(types, event.Type)
+	}
+	require.Equal(t, []EventType{
+		EventSubmitted, EventScheduled, EventExhausted, EventRunStarted, EventPanic, EventRunFinished,
+		EventReplaced, EventScheduled, EventRemoved,
+	}, types)
+
+	require.True(t, events[1].Time.Equal(at))
+	require.True(t, events[3].Time.Equal(at))
+	var panicErr *PanicError
+	require.True(t, errors.As(events[4].Err, &panicErr))
+	require.Equal(t, "oops", panicErr.Value)
+	require.Equal(t, events[4].Err, events[5].Err)
+	require.True(t, events[5].Duration > 0)
+}
+
+func TestCrontab_ListenerPanicRetried(t *testing.T) {
+	var mu sync.Mutex
+	var types []EventType
+	listener := ListenerFunc(func(event Event) {
+		mu.Lock()
+		types = append(types, event.Type)
+		mu.Unlock()
+	})
+	cron := New(WithListener(listener), WithJobWrapper(WrapJobRetry(context.Background(), 1, time.Millisecond), WrapJobRecover()))
+
+	// The panic is reported even if the retry succeeds.
+	var attempts int32
+	require.Nil(t, cron.Submit(context.Background(), "job1", JobFunc(func(ctx context.Context) error {
+		if atomic.AddInt32(&attempts, 1) == 1 {
+			panic("oops")
+		}
+		return nil
+	}), &Interval{Interval: time.Hour}))
+	require.Nil(t, cron.RunNow(context.Background(), "job1"))
+
+	mu.Lock()
+	defer mu.Unlock()
+	require.Equal(t, []EventType{EventSubmitted, EventScheduled, EventRunStarted, EventPanic, EventRunFinished}, types)
+}
+
+func TestCrontab_ListenerGet(t *testing.T) {
+	var cron *Crontab
+	var mu sync.Mutex
+	var infos []JobInfo
+	listener := ListenerFunc(func(event Event) {
+		if event.Type == EventSubmitted || event.Type == EventScheduled {
+			info, ok := cron.Get(event.Key)
+			require.True(t, ok)
+			require.True(t, cron.Has(event.Key))
+			mu.Lock()
+			infos = append(infos, info)
+			mu.Unlock()
+		}
+	})
+	cron = New(WithListener(listener))
+	require.Nil(t, cron.Start())
+	defer cron.Stop()
+
+	// The listener reads the job without deadlock.
+	doneC := make(chan error)
+	go func() {
+		doneC <- cron.Submit(context.Background(), "job1", JobFunc(func(ctx context.Context) error {
+			return nil
+		}), &Interval{Interval: time.Hour})
+	}()
+	select {
+	case err := <-doneC:
+		require.Nil(t, err)
+	case <-time.After(time.Second):
+		t.Fatal("the Submit is blocked by listener")
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	require.Len(t, infos, 2)
+	for _, info := range infos {
+		require.Equal(t, "job1", info.Key)
+	}
+}
@@ -0,0 +1,178 @@
+// Package crontest provides the utilities for testing the code using cron.Crontab.
+//
+// The FakeClock replaces the system clock of Crontab, so that the tests run the
+// scheduled jobs by advancing the clock instead of sleeping:
+//
+//	clock := crontest.NewFakeClock(time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC))
+//	crontab := cron.New(cron.WithClock(clock), cron.WithTimezone(time.UTC))
+//	_ = crontab.Start()
+//	_ = crontab.Submit(ctx, "job1", job, &cron.UnixCron{Express: "0 * * * *"})
+//	clock.Advance(time.Hour * 24) // The job1 runs 24 times.
+package crontest
+
+import (
+	"sync"
+	"time"
+
+	"github.com/yu31/cron-go"
+)
+
+// FakeClock implements the cron.Clock that the time moves forward only by Advance.
+type FakeClock struct {
+	mu     *sync.Mutex
+	idle   *sync.Cond // broadcast when the running decreased.
+	now    time.Time
+	timers []*fakeTimer
+	seq    uint64 // the sequence of the timers created.
+
+	// running is the number of fn being called.
+	running int
+}
+
+// NewFakeClock creates a FakeClock with the current time now.
+func NewFakeClock(now time.Time) *FakeClock {
+	mu := new(sync.Mutex)
+	return &FakeClock{
+		mu:      mu,
+		idle:    sync.NewCond(mu),
+		now:     now,
+		timers:  nil,
+		seq:     0,
+		running: 0,
+	}
+}
+
+// Now implements cron.Clock.
+func (c *FakeClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.now
+}
+
+// AtFunc implements cron.Clock. The fn is called in its own goroutine if the
+// time t is not after the current time, otherwise it is called by Advance.
+func (c *FakeClock) AtFunc(t time.Time, fn func()) cron.Timer {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.seq++
+	ft := &fakeTimer{clock: c, at: t, seq: c.seq, fn: fn}
+	if t.After(c.now) {
+		c.timers = append(c.timers, ft)
+		return ft
+	}
+	c.running++
+	go c.call(fn)
+	return ft
+}
+
+// Advance moves the current time forward by d. The calls due in the window are
+// fired one by one in order of their time, and the calls created with the same
+// time are fired in order of creation. Each call is made synchronously with the
+// current time set to its time, so the jobs of Crontab are completed before
+// Advance returns.
+//
+// Before each call and returning, Advance waits for the calls started in their
+// own goroutine with BlockUntilIdle. It must not be called concurrently, or by
+// the job run by the FakeClock.
+func (c *FakeClock) Advance(d time.Duration) {
+	c.mu.Lock()
+	target := c.now.Add(d)
+	c.mu.Unlock()
+
+	for {
+		c.BlockUntilIdle()
+
+		c.mu.Lock()
+		ft := c.popDue(target)
+		if ft == nil {
+			if target.After(c.now) {
+				c.now = target
+			}
+			c.mu.Unlock()
+			return
+		}
+		if ft.at.After(c.now) {
+			c.now = ft.at
+		}
+		c.running++
+		c.mu.Unlock()
+
+		c.call(ft.fn)
+	}
+}
+
+// BlockUntilIdle waits until all calls started are completed, including the calls
+// that were due when created, e.g. the job submitted with a cron.Appoint in past,
+// and the runs missed while a job was paused.
+func (c *FakeClock) BlockUntilIdle() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for c.running > 0 {
+		c.idle.Wait()
+	}
+}
+
+// call calls the fn and decreases the running. The running must be increased
+// before.
+func (c *FakeClock) call(fn func()) {
+	defer func() {
+		c.mu.Lock()
+		c.running--
+		c.idle.Broadcast()
+		c.mu.Unlock()
+	}()
+	fn()
+}
+
+// popDue removes and returns the earliest timer not after the time t, or nil if
+// no timers due. It must be called with c.mu held.
+func (c *FakeClock) popDue(t time.Time) *fakeTimer {
+	index := -1
+	for i, ft := range c.timers {
+		if ft.at.After(t) {
+			continue
+		}
+		if index == -1 || ft.before(c.timers[index]) {
+			index = i
+		}
+	}
+	if index == -1 {
+		return nil
+	}
+	ft := c.timers[index]
+	c.timers = append(c.timers[:index], c.timers[index+1:]...)
+	return ft
+}
+
+// remove removes the timer ft if it has not been fired.
+func (c *FakeClock) remove(ft *fakeTimer) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for i := range c.timers {
+		if c.timers[i] == ft {
+			c.timers = append(c.timers[:i], c.timers[i+1:]...)
+			return
+		}
+	}
+}
+
+// fakeTimer is a single call created by FakeClock.AtFunc.
+type fakeTimer struct {
+	clock *FakeClock
+	at    time.Time
+	seq   uint64
+	fn    func()
+}
+
+// Close implements cron.Timer.
+func (ft *fakeTimer) Close() {
+	ft.clock.remove(ft)
+}
+
+// before reports whether the timer ft should be fired before the other.
+func (ft *fakeTimer) before(other *fakeTimer) bool {
+	if ft.at.Equal(other.at) {
+		return ft.seq < other.seq
+	}
+	return ft.at.Before(other.at)
+}
@@ -0,0 +1,220 @@
+package crontest
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/yu31/cron-go"
+)
+
+var begin = time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC)
+
+func TestFakeClock(t *testing.T) {
+	clock := NewFakeClock(begin)
+	require.Equal(t, begin, clock.Now())
+
+	var fired []int
+	var at []time.Time
+	record := func(i int) func() {
+		return func() {
+			fired = append(fired, i)
+			at = append(at, clock.Now())
+		}
+	}
+	clock.AtFunc(begin.Add(time.Second*2), record(2))
+	clock.AtFunc(begin.Add(time.Second), record(0))
+	clock.AtFunc(begin.Add(time.Second), record(1))
+	clock.AtFunc(begin.Add(time.Second*3), record(3)).Close()
+	clock.AtFunc(begin.Add(time.Second*4), func() {
+		// The call created while advancing is fired in the same window.
+		clock.AtFunc(clock.Now().Add(time.Second), record(4))
+	})
+
+	clock.Advance(time.Second * 5)
+	require.Equal(t, []int{0, 1, 2, 4}, fired)
+	require.Equal(t, []time.Time{
+		begin.Add(time.Second), begin.Add(time.Second), begin.Add(time.Second * 2), begin.Add(time.Second * 5),
+	}, at)
+	require.Equal(t, begin.Add(time.Second*5), clock.Now())
+
+	// The call due is fired in its own goroutine.
+	doneC := make(chan struct{})
+	clock.AtFunc(begin, func() {
+		time.Sleep(time.Millisecond * 20)
+		close(doneC)
+	})
+	clock.BlockUntilIdle()
+	select {
+	case <-doneC:
+	default:
+		t.Fatal("the call is not completed")
+	}
+}
+
+func TestFakeClock_Crontab(t *testing.T) {
+	clock := NewFakeClock(begin)
+	crontab := cron.New(cron.WithClock(clock), cron.WithTimezone(time.UTC), cron.WithLogger(cron.DiscardLogger))
+	require.Nil(t, crontab.Start())
+	defer crontab.Stop()
+
+	var mu sync.Mutex
+	runs := make(map[string][]time.Time)
+	nows := make(map[string][]time.Time)
+	job := func(key string) cron.Job {
+		return cron.JobFunc(func(ctx context.Context) error {
+			planned, _ := cron.PlannedTime(ctx)
+			mu.Lock()
+			runs[key] = append(runs[key], planned)
+			nows[key] = append(nows[key], clock.Now())
+			mu.Unlock()
+			return nil
+		})
+	}
+
+	ctx := context.Background()
+	require.Nil(t, crontab.Submit(ctx, "unix_cron", job("unix_cron"), &cron.UnixCron{Express: "0 */6 * * *"}))
+	require.Nil(t, crontab.Submit(ctx, "interval", job("interval"), &cron.Interval{Interval: time.Hour * 8}))
+	require.Nil(t, crontab.Submit(ctx, "appoint", job("appoint"), &cron.Appoint{Time: begin.Add(time.Hour * 5)}))
+
+	clock.Advance(time.Hour * 24)
+	require.Equal(t, []time.Time{
+		begin.Add(time.Hour * 6), begin.Add(time.Hour * 12), begin.Add(time.Hour * 18), begin.Add(time.Hour * 24),
+	}, runs["unix_cron"])
+	require.Equal(t, []time.Time{
+		begin.Add(time.Hour * 8), begin.Add(time.Hour * 16), begin.Add(time.Hour * 24),
+	}, runs["interval"])
+	require.Equal(t, []time.Time{begin.Add(time.Hour * 5)}, runs["appoint"])
+	// The jobs run at the planned time of the clock.
+	for key := range runs {
+		require.Equal(t, runs[key], nows[key])
+	}
+
+	info, _ := crontab.Get("unix_cron")
+	require.Equal(t, begin.Add(time.Hour*30), info.Next)
+
+	// The Appoint in past runs immediately.
+	require.Nil(t, crontab.Submit(ctx, "past", job("past"), &cron.Appoint{Time: begin}))
+	clock.BlockUntilIdle()
+	require.Equal(t, []time.Time{begin}, runs["past"])
+
+	// No runs after stop.
+	crontab.Stop()
+	clock.Advance(time.Hour * 24)
+	require.Len(t, runs["unix_cron"], 4)
+}
+
+func TestFakeClock_ResumeMisfire(t *testing.T) {
+	clock := NewFakeClock(begin)
+	crontab := cron.New(
+		cron.WithClock(clock),
+		cron.WithTimezone(time.UTC),
+		cron.WithLogger(cron.DiscardLogger),
+		cron.WithResumeMisfire(cron.MisfireRunAll),
+	)
+	require.Nil(t, crontab.Start())
+	defer crontab.Stop()
+
+	var mu sync.Mutex
+	var runs []time.Time
+	job := cron.JobFunc(func(ctx context.Context) error {
+		planned, _ := cron.PlannedTime(ctx)
+		mu.Lock()
+		runs = append(runs, planned)
+		mu.Unlock()
+		return nil
+	})
+	require.Nil(t, crontab.Submit(context.Background(), "job1", job, &cron.UnixCron{Express: "@hourly"}))
+	require.Nil(t, crontab.Pause("job1"))
+	clock.Advance(time.Hour*3 + time.Minute)
+	require.Empty(t, runs)
+
+	// The missed runs are caught up in order.
+	require.Nil(t, crontab.Resume("job1"))
+	clock.BlockUntilIdle()
+	require.Equal(t, []time.Time{begin.Add(time.Hour), begin.Add(time.Hour * 2), begin.Add(time.Hour * 3)}, runs)
+}
+
+func TestFakeClock_ResumeMisfirePolicy(t *testing.T) {
+	cases := []struct {
+		policy  cron.MisfirePolicy
+		planned []time.Time
+	}{
+		{cron.MisfireSkip, nil},
+		{cron.MisfireRunOnce, []time.Time{begin.Add(time.Minute * 3)}},
+		{cron.MisfireRunAll, []time.Time{begin.Add(time.Minute), begin.Add(time.Minute * 2), begin.Add(time.Minute * 3)}},
+	}
+	for _, c := range cases {
+		clock := NewFakeClock(begin)
+		crontab := cron.New(
+			cron.WithClock(clock),
+			cron.WithTimezone(time.UTC),
+			cron.WithLogger(cron.DiscardLogger),
+			cron.WithResumeMisfire(c.policy),
+		)
+		require.Nil(t, crontab.Start())
+
+		var planned []time.Time
+		require.Nil(t, crontab.Submit(context.Background(), "job1", cron.JobFunc(func(ctx context.Context) error {
+			p, _ := cron.PlannedTime(ctx)
+			planned = append(planned, p)
+			return nil
+		}), &cron.Interval{Interval: time.Minute}))
+
+		// The runs missed while paused are handled by the policy on Resume.
+		require.Nil(t, crontab.Pause("job1"))
+		clock.Advance(time.Minute*3 + time.Second)
+		require.Nil(t, crontab.Resume("job1"))
+		clock.BlockUntilIdle()
+		require.Equal(t, c.planned, planned)
+
+		info, _ := crontab.Get("job1")
+		require.True(t, info.Next.After(clock.Now()))
+		crontab.Stop()
+	}
+}
+
+func TestFakeClock_RestoreMisfire(t *testing.T) {
+	store := cron.NewMemoryStore()
+	registry := cron.NewRegistry()
+	var mu sync.Mutex
+	var runs []time.Time
+	registry.Register("record", func(payload []byte) (cron.Job, error) {
+		return cron.JobFunc(func(ctx context.Context) error {
+			planned, _ := cron.PlannedTime(ctx)
+			mu.Lock()
+			runs = append(runs, planned)
+			mu.Unlock()
+			return nil
+		}), nil
+	})
+	newCrontab := func(clock *FakeClock) *cron.Crontab {
+		return cron.New(
+			cron.WithClock(clock),
+			cron.WithTimezone(time.UTC),
+			cron.WithLogger(cron.DiscardLogger),
+			cron.WithStore(store, registry),
+		)
+	}
+
+	// The process is down before the first run completed.
+	crontab := newCrontab(NewFakeClock(begin.Add(time.Hour)))
+	require.Nil(t, crontab.Start())
+	require.Nil(t, crontab.SubmitPersistent(context.Background(), "job1", "record", nil,
+		&cron.UnixCron{Express: "@daily"}, cron.WithMisfire(cron.MisfireRunAll, time.Time{})))
+	crontab.Stop()
+
+	// The runs missed since the first planned time are caught up on reload.
+	clock := NewFakeClock(begin.Add(time.Hour * 73))
+	crontab = newCrontab(clock)
+	require.Nil(t, crontab.Start())
+	defer crontab.Stop()
+	clock.BlockUntilIdle()
+	require.Equal(t, []time.Time{begin.Add(time.Hour * 24), begin.Add(time.Hour * 48), begin.Add(time.Hour * 72)}, runs)
+
+	info, _ := crontab.Get("job1")
+	require.Equal(t, begin.Add(time.Hour*96), info.Next)
+}
@@ -0,0 +1,104 @@
+package cron
+
+import (
+	"context"
+	"time"
+
+	"github.com/yu31/timewheel-go"
+)
+
+// Driver is the execution backend that fires the activations of the jobs in Crontab.
+// The default is the timewheel driver returned by NewTimeWheelDriver, it can be
+// replaced with WithDriver, e.g. the heap driver returned by NewHeapDriver.
+type Driver interface {
+	// Start starts firing the activations.
+	Start()
+
+	// Stop stops firing the activations. It does not wait for the running fn.
+	Stop()
+
+	// Schedule calls fn each time the schedule expires, the first time at next.
+	// The fn is called with the planned time and the next activation time of the
+	// schedule, the zero next means the schedule is exhausted. The built-in drivers
+	// call fn in its own goroutine, except the driver of the Clock set by WithClock
+	// that calls fn in the way of Clock.AtFunc.
+	// It returns a Timer that can be used to cancel the calls using its Close method.
+	Schedule(schedule Schedule, next time.Time, fn func(planned time.Time, next time.Time)) Timer
+}
+
+// minIntervaler is implemented by the Driver that cannot fire the activations
+// of a schedule closer than a minimum interval.
+type minIntervaler interface {
+	minInterval() time.Duration
+}
+
+// driverMinInterval returns the minimum interval of the driver, zero means
+// no limited.
+func driverMinInterval(d Driver) time.Duration {
+	if m, ok := d.(minIntervaler); ok {
+		return m.minInterval()
+	}
+	return 0
+}
+
+// NewTimeWheelDriver creates a Driver with a hierarchical timing wheel of 1ms tick.
+// It suits a large number of jobs, while the activations are fired at the precision
+// of the tick, so the Interval shorter than 10ms is rejected by Crontab.
+func NewTimeWheelDriver() Driver {
+	return &timeWheelDriver{tw: timewheel.Default()}
+}
+
+// timeWheelDriver implements Driver with timewheel.
+type timeWheelDriver struct {
+	tw *timewheel.TimeWheel
+}
+
+// Start implements Driver.
+func (d *timeWheelDriver) Start() {
+	d.tw.Start()
+}
+
+// Stop implements Driver.
+func (d *timeWheelDriver) Stop() {
+	d.tw.Stop()
+}
+
+// minInterval implements minIntervaler.
+func (d *timeWheelDriver) minInterval() time.Duration {
+	return time.Millisecond * 10
+}
+
+// Schedule implements Driver.
+func (d *timeWheelDriver) Schedule(schedule Schedule, next time.Time, fn func(planned time.Time, next time.Time)) Timer {
+	return newScheduleTimer(d.atFunc, schedule, next, fn)
+}
+
+// atFunc calls fn at the time t with timewheel.
+func (d *timeWheelDriver) atFunc(t time.Time, fn func()) Timer {
+	if !t.After(time.Now()) {
+		// Not to wait for the timewheel to advance.
+		go fn()
+		return firedTimer{}
+	}
+	return d.tw.TimeFunc(context.Background(), t, func(context.Context) error {
+		fn()
+		return nil
+	})
+}
+
+// clockDriver implements Driver with the Clock set by WithClock. The fn is called
+// by Clock.AtFunc, i.e. synchronously in FakeClock.Advance.
+type clockDriver struct {
+	clock Clock
+}
+
+// Start implements Driver.
+func (d *clockDriver) Start() {}
+
+// Stop implements Driver.
+func (d *clockDriver) Stop() {}
+
+// Schedule implements Driver.
+func (d *clockDriver) Schedule(schedule Schedule, next time.Time, fn func(planned time.Time, next time.Time)) Timer {
+	return newScheduleTimer(d.clock.AtFunc, schedule, next, fn)
+}
@@ -0,0 +1,151 @@
+package cron
+
+import (
+	"container/heap"
+	"sync"
+	"time"
+)
+
+// NewHeapDriver creates a Driver with a min-heap of activations and a single
+// time.Timer that wakes at the earliest one. The activations are fired without
+// the tick granularity of timewheel, it suits the services with a few jobs that
+// need precise timing.
+func NewHeapDriver() Driver {
+	return &heapDriver{
+		mu:      new(sync.Mutex),
+		items:   nil,
+		wakeup:  nil,
+		started: false,
+	}
+}
+
+// heapDriver implements Driver with a min-heap and time.Timer.
+type heapDriver struct {
+	// The fields below are protected by mu.
+	mu      *sync.Mutex
+	items   heapItems
+	wakeup  *time.Timer // fires at the earliest activation, nil if no activations.
+	started bool
+}
+
+// Start implements Driver.
+func (d *heapDriver) Start() {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.started = true
+	d.reset()
+}
+
+// Stop implements Driver.
+func (d *heapDriver) Stop() {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.started = false
+	d.reset()
+}
+
+// Schedule implements Driver.
+func (d *heapDriver) Schedule(schedule Schedule, next time.Time, fn func(planned time.Time, next time.Time)) Timer {
+	return newScheduleTimer(d.atFunc, schedule, next, fn)
+}
+
+// atFunc calls fn at the time t. Same as timewheel, the expired activation
+// is fired immediately even if the driver is not started.
+func (d *heapDriver) atFunc(t time.Time, fn func()) Timer {
+	if !t.After(time.Now()) {
+		go fn()
+		return firedTimer{}
+	}
+	item := &heapItem{driver: d, at: t, fn: fn, index: -1}
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	heap.Push(&d.items, item)
+	if item.index == 0 {
+		d.reset()
+	}
+	return item
+}
+
+// fire fires the expired activations and waits for the next one.
+func (d *heapDriver) fire() {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if !d.started {
+		return
+	}
+	now := time.Now()
+	for len(d.items) != 0 && !d.items[0].at.After(now) {
+		item := heap.Pop(&d.items).(*heapItem)
+		go item.fn()
+	}
+	d.reset()
+}
+
+// reset stops the wakeup timer and resets it to the earliest activation if started.
+// It must be called with d.mu held.
+func (d *heapDriver) reset() {
+	if d.wakeup != nil {
+		d.wakeup.Stop()
+		d.wakeup = nil
+	}
+	if !d.started || len(d.items) == 0 {
+		return
+	}
+	d.wakeup = time.AfterFunc(time.Until(d.items[0].at), d.fire)
+}
+
+// remove removes the activation if it has not been fired.
+func (d *heapDriver) remove(item *heapItem) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if item.index < 0 {
+		return
+	}
+	earliest := item.index == 0
+	heap.Remove(&d.items, item.index)
+	if earliest {
+		d.reset()
+	}
+}
+
+// heapItem is a single activation in heapDriver.
+type heapItem struct {
+	driver *heapDriver
+	at     time.Time
+	fn     func()
+	index  int // the index in heap, -1 if removed.
+}
+
+// Close implements Timer.
+func (item *heapItem) Close() {
+	item.driver.remove(item)
+}
+
+// heapItems implements heap.Interface ordered by the activation time.
+type heapItems []*heapItem
+
+func (h heapItems) Len() int           { return len(h) }
+func (h heapItems) Less(i, j int) bool { return h[i].at.Before(h[j].at) }
+
+func (h heapItems) Swap(i, j int) {
+	h[i], h[j] = h[j], h[i]
+	h[i].index = i
+	h[j].index = j
+}
+
+func (h *heapItems) Push(x interface{}) {
+	item := x.(*heapItem)
+	item.index = len(*h)
+	*h = append(*h, item)
+}
+
+func (h *heapItems) Pop() interface{} {
+	old := *h
+	n := len(old)
+	item := old[n-1]
+	old[n-1] = nil
+	item.index = -1
+	*h = old[:n-1]
+	return item
+}
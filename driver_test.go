@@ -0,0 +1,130 @@
+package cron
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestDriver(t *testing.T) {
+	drivers := map[string]func() Driver{
+		"timewheel": NewTimeWheelDriver,
+		"heap":      NewHeapDriver,
+	}
+	for name, newDriver := range drivers {
+		t.Run(name, func(t *testing.T) {
+			driver := newDriver()
+			driver.Start()
+			defer driver.Stop()
+
+			var mu sync.Mutex
+			var planned []time.Time
+			var fired []time.Time
+			record := func(p time.Time, next time.Time) {
+				mu.Lock()
+				planned = append(planned, p)
+				fired = append(fired, time.Now())
+				mu.Unlock()
+			}
+			count := func() int {
+				mu.Lock()
+				defer mu.Unlock()
+				return len(planned)
+			}
+
+			begin := time.Now()
+			schedule := &Interval{Interval: time.Millisecond * 20, End: begin.Add(time.Millisecond * 70)}
+			driver.Schedule(schedule, schedule.Next(begin), record)
+			require.Eventually(t, func() bool { return count() == 3 }, time.Second, time.Millisecond)
+			time.Sleep(time.Millisecond * 50)
+
+			mu.Lock()
+			require.Equal(t, []time.Time{
+				begin.Add(time.Millisecond * 20), begin.Add(time.Millisecond * 40), begin.Add(time.Millisecond * 60),
+			}, planned)
+			for i := range planned {
+				require.False(t, fired[i].Before(planned[i].Truncate(time.Millisecond)))
+			}
+			mu.Unlock()
+
+			// No calls after closed.
+			timer := driver.Schedule(schedule, time.Now().Add(time.Millisecond*20), record)
+			timer.Close()
+			time.Sleep(time.Millisecond * 50)
+			require.Equal(t, 3, count())
+
+			// The expired activation is fired immediately.
+			driver.Schedule(ScheduleFunc(func(time.Time) time.Time { return time.Time{} }), begin, record)
+			require.Eventually(t, func() bool { return count() == 4 }, time.Second, time.Millisecond)
+		})
+	}
+}
+
+func TestHeapDriver_Stop(t *testing.T) {
+	driver := NewHeapDriver()
+	firedC := make(chan time.Time, 8)
+	schedule := &Interval{Interval: time.Millisecond * 20}
+	next := time.Now().Add(time.Millisecond * 20)
+	driver.Schedule(schedule, next, func(planned time.Time, next time.Time) { firedC <- planned })
+
+	// Not fired before started.
+	time.Sleep(time.Millisecond * 40)
+	require.Len(t, firedC, 0)
+
+	driver.Start()
+	require.Equal(t, next, <-firedC)
+	driver.Stop()
+	time.Sleep(time.Millisecond * 40)
+	for len(firedC) != 0 {
+		<-firedC
+	}
+	time.Sleep(time.Millisecond * 40)
+	require.Len(t, firedC, 0)
+}
+
+func TestCrontab_HeapDriver(t *testing.T) {
+	cron := New(WithDriver(NewHeapDriver()))
+	require.Nil(t, cron.Start())
+	defer cron.Stop()
+
+	var mu sync.Mutex
+	var lateness []time.Duration
+	job := JobFunc(func(ctx context.Context) error {
+		planned, _ := PlannedTime(ctx)
+		mu.Lock()
+		lateness = append(lateness, time.Since(planned))
+		mu.Unlock()
+		return nil
+	})
+	require.Nil(t, cron.Submit(context.Background(), "job1", job, &Interval{Interval: time.Millisecond * 15}))
+	require.Eventually(t, func() bool {
+		mu.Lock()
+		defer mu.Unlock()
+		return len(lateness) >= 3
+	}, time.Second, time.Millisecond*5)
+
+	mu.Lock()
+	defer mu.Unlock()
+	for _, d := range lateness {
+		require.True(t, d >= 0)
+	}
+}
+
+func TestCrontab_MinInterval(t *testing.T) {
+	job := JobFunc(func(ctx context.Context) error { return nil })
+	schedule := &Interval{Interval: time.Millisecond * 5}
+
+	// The timewheel driver cannot fire at the interval shorter than its minimum.
+	cron := New()
+	require.NotNil(t, cron.Submit(context.Background(), "job1", job, schedule))
+	require.NotNil(t, cron.Submit(context.Background(), "job1", job, WithJitter(schedule, time.Millisecond)))
+	require.Nil(t, cron.Submit(context.Background(), "job1", job, &Interval{Interval: time.Millisecond * 10}))
+
+	// The heap driver has no minimum.
+	cron = New(WithDriver(NewHeapDriver()))
+	require.Nil(t, cron.Submit(context.Background(), "job1", job, schedule))
+	require.Nil(t, cron.Submit(context.Background(), "job2", job, WithSplay(schedule, time.Millisecond)))
+}
@@ -0,0 +1,181 @@
+package cron
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// JobInfo is a snapshot of a job registered in the Crontab.
+type JobInfo struct {
+	// Key is the unique key of the job.
+	Key string
+
+	// Schedule is the Schedule that the job submitted with.
+	Schedule Schedule
+
+	// Submitted is the time of the job submitted.
+	Submitted time.Time
+
+	// Prev is the planned time of the latest run.
+	// Zero means the job has not been run yet.
+	Prev time.Time
+
+	// Next is the planned time of the next run.
+	// Zero means the schedule is exhausted.
+	Next time.Time
+
+	// Runs is the number of times the job has been started.
+	Runs int64
+
+	// LastError is the error returned by the latest completed run.
+	LastError error
+
+	// Paused indicates whether the job is paused.
+	Paused bool
+}
+
+// entry represents a job registered in the Crontab.
+type entry struct {
+	key      string
+	job      Job // the job decorated by jobChain.
+	schedule Schedule
+	ctx      context.Context
+	cancel   context.CancelFunc
+	timer    Timer
+	record   *Record // not nil if the job is persistent.
+	priority Priority
+	missed   []time.Time // the missed runs to catch up on Start, protected by Crontab.mu.
+	closed   bool        // true if removed or replaced, protected by Crontab.runMu.
+
+	// The fields below are protected by mu.
+	mu        *sync.Mutex
+	submitted time.Time
+	prev      time.Time
+	next      time.Time
+	runs      int64
+	lastErr   error
+	paused    bool
+	planned   time.Time // the planned time of next run when paused.
+}
+
+func newEntry(ctx context.Context, key string, job Job, schedule Schedule, submitted time.Time) *entry {
+	ctxCancel, cancelFunc := context.WithCancel(ctx)
+	return &entry{
+		key:       key,
+		job:       job,
+		schedule:  schedule,
+		ctx:       ctxCancel,
+		cancel:    cancelFunc,
+		timer:     nil,
+		mu:        new(sync.Mutex),
+		submitted: submitted,
+	}
+}
+
+// Next implements Schedule and records the next activation time.
+func (e *entry) Next(prev time.Time) time.Time {
+	next := e.schedule.Next(prev)
+	e.setNext(next)
+	return next
+}
+
+// completed updates the record of persistent job with the planned time of the
+// completed run. It returns a copy of record, or nil if the job is not persistent.
+func (e *entry) completed(planned time.Time) *Record {
+	if e.record == nil {
+		return nil
+	}
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if planned.After(e.record.LastRun) {
+		e.record.LastRun = planned
+	}
+	e.record.NextRun = e.next
+	record := *e.record
+	return &record
+}
+
+// setNext records the next activation time.
+func (e *entry) setNext(next time.Time) {
+	e.mu.Lock()
+	e.next = next
+	e.mu.Unlock()
+}
+
+// getNext returns the next activation time.
+func (e *entry) getNext() time.Time {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return e.next
+}
+
+// started records the start of a run with the planned time.
+func (e *entry) started(planned time.Time) {
+	e.mu.Lock()
+	e.prev = planned
+	e.runs++
+	e.mu.Unlock()
+}
+
+// finished records the result of a run.
+func (e *entry) finished(err error) {
+	e.mu.Lock()
+	e.lastErr = err
+	e.mu.Unlock()
+}
+
+// pause stops the timer and saves the planned time of next run.
+// It must be called with the Crontab.mu held.
+func (e *entry) pause() {
+	if e.timer == nil {
+		return
+	}
+	// The timer must be closed without e.mu held, since it calls e.Next.
+	e.timer.Close()
+	e.timer = nil
+
+	e.mu.Lock()
+	e.paused = true
+	e.planned = e.next
+	e.next = time.Time{}
+	e.mu.Unlock()
+}
+
+// resume returns the planned time of next run when paused. The caller must
+// set the new timer after. It must be called with the Crontab.mu held.
+func (e *entry) resume() (planned time.Time, ok bool) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if !e.paused {
+		return time.Time{}, false
+	}
+	e.paused = false
+	planned = e.planned
+	e.planned = time.Time{}
+	return planned, true
+}
+
+// close stops the timer. The context of running jobs is canceled by Crontab
+// on Shutdown, or after they completed if the job is removed or replaced.
+func (e *entry) close() {
+	if e.timer != nil {
+		e.timer.Close()
+	}
+}
+
+// info returns a snapshot of the entry.
+func (e *entry) info() JobInfo {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return JobInfo{
+		Key:       e.key,
+		Schedule:  e.schedule,
+		Submitted: e.submitted,
+		Prev:      e.prev,
+		Next:      e.next,
+		Runs:      e.runs,
+		LastError: e.lastErr,
+		Paused:    e.paused,
+	}
+}
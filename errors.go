@@ -0,0 +1,119 @@
+package cron
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+var (
+	// ErrJobNotFound is returned if the job with specified key does not exist.
+	ErrJobNotFound = errors.New("cron: job not found")
+
+	// ErrShutdown is returned if the Crontab has been shutdown.
+	ErrShutdown = errors.New("cron: crontab is shutdown")
+
+	// ErrJobSkipped is returned by Crontab.RunNow if the run is skipped by the
+	// JobWrapper, e.g. WrapJobSkipIfRunning or WrapJobSemaphore.
+	ErrJobSkipped = errors.New("cron: job run skipped")
+
+	// ErrJobTimeout is matched by the *TimeoutError returned by the job decorated
+	// with WrapJobTimeout, use errors.Is to check it.
+	ErrJobTimeout = errors.New("cron: job run timeout")
+)
+
+// ShutdownError is returned by Crontab.Shutdown if the context is done
+// before all running jobs completed.
+type ShutdownError struct {
+	// Keys is the sorted key of jobs still running.
+	Keys []string
+
+	// Err is the error of the context.
+	Err error
+}
+
+func (e *ShutdownError) Error() string {
+	return fmt.Sprintf("cron: shutdown: %v, jobs still running: %s", e.Err, strings.Join(e.Keys, ", "))
+}
+
+func (e *ShutdownError) Unwrap() error {
+	return e.Err
+}
+
+// TimeoutError is returned by the job decorated with WrapJobTimeout if the job
+// fails after the timeout expired. It matches ErrJobTimeout by errors.Is, and
+// unwraps to the error of the job.
+type TimeoutError struct {
+	// Timeout is the timeout of WrapJobTimeout.
+	Timeout time.Duration
+
+	// Err is the error returned by the job, or the error of the context if the
+	// job is abandoned.
+	Err error
+}
+
+func (e *TimeoutError) Error() string {
+	return fmt.Sprintf("%v after %s: %v", ErrJobTimeout, e.Timeout, e.Err)
+}
+
+func (e *TimeoutError) Unwrap() error {
+	return e.Err
+}
+
+// Is reports whether the target is ErrJobTimeout.
+func (e *TimeoutError) Is(target error) bool {
+	return target == ErrJobTimeout
+}
+
+// PanicError is returned by the job decorated with WrapJobRecover if the job panics.
+type PanicError struct {
+	// Value is the value passed to panic.
+	Value interface{}
+
+	// Stack is the stack trace of the panic.
+	Stack []byte
+}
+
+func (e *PanicError) Error() string {
+	return fmt.Sprintf("cron: job run panic with error: %v", e.Value)
+}
+
+// Unwrap returns the Value if it is an error.
+func (e *PanicError) Unwrap() error {
+	err, _ := e.Value.(error)
+	return err
+}
+
+// RetryError is returned by the job decorated with WrapJobRetryPolicy if all
+// attempts failed.
+type RetryError struct {
+	// Errors is the errors of all attempts in order.
+	Errors []error
+}
+
+func (e *RetryError) Error() string {
+	msgs := make([]string, len(e.Errors))
+	for i, err := range e.Errors {
+		msgs[i] = err.Error()
+	}
+	return fmt.Sprintf("cron: job run failed after %d attempts: %s", len(e.Errors), strings.Join(msgs, "; "))
+}
+
+// Unwrap returns the error of the last attempt.
+func (e *RetryError) Unwrap() error {
+	if len(e.Errors) == 0 {
+		return nil
+	}
+	return e.Errors[len(e.Errors)-1]
+}
+
+// Is reports whether the error of any attempt matches the target.
+func (e *RetryError) Is(target error) bool {
+	for _, err := range e.Errors {
+		if errors.Is(err, target) {
+			return true
+		}
+	}
+	return false
+}
@@ -3,6 +3,7 @@ package main
 import (
 	"context"
 	"fmt"
+	"log"
 	"time"
 
 	"github.com/yu31/cron-go"
@@ -10,10 +11,12 @@ import (
 
 func main() {
 	crontab := cron.New()
-	crontab.Start()
+	if err := crontab.Start(); err != nil {
+		log.Fatal(err)
+	}
 	defer crontab.Stop()
 
-	crontab.Submit(
+	if err := crontab.Submit(
 		context.Background(),
 		"once1",
 		&cron.Task{
@@ -24,9 +27,11 @@ func main() {
 			},
 		},
 		&cron.Appoint{Time: time.Now().Add(time.Second)},
-	)
+	); err != nil {
+		log.Fatal(err)
+	}
 
-	crontab.Submit(
+	if err := crontab.Submit(
 		context.Background(),
 		"unix_cron1",
 		&cron.Task{
@@ -41,9 +46,11 @@ func main() {
 			End:     time.Unix(2556144000, 0),
 			Express: "* * * * *",
 		},
-	)
+	); err != nil {
+		log.Fatal(err)
+	}
 
-	crontab.Submit(
+	if err := crontab.Submit(
 		context.Background(),
 		"unix_cron2",
 		&cron.Task{
@@ -58,9 +65,11 @@ func main() {
 			End:     time.Unix(2556144000, 0),
 			Express: "* * * * *",
 		},
-	)
+	); err != nil {
+		log.Fatal(err)
+	}
 
-	crontab.Submit(
+	if err := crontab.Submit(
 		context.Background(),
 		"interval1",
 		&cron.Task{
@@ -75,9 +84,11 @@ func main() {
 			End:      time.Unix(2556144000, 0),
 			Interval: time.Second,
 		},
-	)
+	); err != nil {
+		log.Fatal(err)
+	}
 
-	crontab.Submit(
+	if err := crontab.Submit(
 		context.Background(),
 		"interval2",
 		&cron.Task{
@@ -92,7 +103,9 @@ func main() {
 			End:      time.Unix(2556144000, 0),
 			Interval: time.Second * 3,
 		},
-	)
+	); err != nil {
+		log.Fatal(err)
+	}
 
 	time.Sleep(time.Second * 120)
 }
@@ -0,0 +1,141 @@
+package cron
+
+import (
+	"errors"
+	"hash/fnv"
+	"math/rand"
+	"sync"
+	"time"
+)
+
+// WithJitter returns a Schedule that delays each activation of the schedule by
+// a random duration in [0, max), so that the jobs with the same schedule do not
+// run at the same moment. The max should be less than the interval between
+// activations of the schedule.
+func WithJitter(schedule Schedule, max time.Duration) Schedule {
+	return &jitterSchedule{
+		schedule: schedule,
+		max:      max,
+		mu:       new(sync.Mutex),
+	}
+}
+
+// jitterSchedule delays each activation of schedule by a random duration.
+type jitterSchedule struct {
+	schedule Schedule
+	max      time.Duration
+
+	// The latest activation with and without the delay, so that the delay
+	// will not be accumulated when the next activation computed from it.
+	mu      *sync.Mutex
+	delayed time.Time
+	planned time.Time
+}
+
+// Next implements Schedule.
+func (s *jitterSchedule) Next(prev time.Time) time.Time {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if !s.delayed.IsZero() && prev.Equal(s.delayed) {
+		prev = s.planned
+	}
+	return s.delay(s.schedule.Next(prev))
+}
+
+// first implements firstActivator.
+func (s *jitterSchedule) first(now time.Time) time.Time {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.delay(firstActivation(s.schedule, now))
+}
+
+// delay returns the planned activation with a random delay. It must be called
+// with s.mu held.
+func (s *jitterSchedule) delay(planned time.Time) time.Time {
+	if planned.IsZero() || s.max <= 0 {
+		return planned
+	}
+	s.planned = planned
+	s.delayed = planned.Add(time.Duration(rand.Int63n(int64(s.max))))
+	return s.delayed
+}
+
+// Validate implements Validator.
+func (s *jitterSchedule) Validate() error {
+	if s.max <= 0 {
+		return errors.New("cron: max jitter must be positive")
+	}
+	return validateSchedule(s.schedule)
+}
+
+// checkInterval implements intervalChecker.
+func (s *jitterSchedule) checkInterval(min time.Duration) error {
+	return checkInterval(s.schedule, min)
+}
+
+// bindKey implements keyBinder.
+func (s *jitterSchedule) bindKey(key string) error {
+	return bindKey(s.schedule, key)
+}
+
+// WithSplay returns a Schedule that delays each activation of the schedule by
+// a stable duration in [0, max) derived from the job key, so that the jobs with
+// the same schedule spread while each job always runs at the same offset.
+// The job key is set by Crontab when submitted. The max should be less than
+// the interval between activations of the schedule.
+func WithSplay(schedule Schedule, max time.Duration) Schedule {
+	s := &splaySchedule{
+		schedule: schedule,
+		max:      max,
+	}
+	_ = s.bindKey("")
+	return s
+}
+
+// splaySchedule delays each activation of schedule by a stable offset.
+type splaySchedule struct {
+	schedule Schedule
+	max      time.Duration
+	offset   time.Duration
+}
+
+// Next implements Schedule.
+func (s *splaySchedule) Next(prev time.Time) time.Time {
+	planned := s.schedule.Next(prev.Add(-s.offset))
+	if planned.IsZero() {
+		return planned
+	}
+	return planned.Add(s.offset)
+}
+
+// first implements firstActivator.
+func (s *splaySchedule) first(now time.Time) time.Time {
+	planned := firstActivation(s.schedule, now.Add(-s.offset))
+	if planned.IsZero() {
+		return planned
+	}
+	return planned.Add(s.offset)
+}
+
+// Validate implements Validator.
+func (s *splaySchedule) Validate() error {
+	if s.max <= 0 {
+		return errors.New("cron: max splay must be positive")
+	}
+	return validateSchedule(s.schedule)
+}
+
+// checkInterval implements intervalChecker.
+func (s *splaySchedule) checkInterval(min time.Duration) error {
+	return checkInterval(s.schedule, min)
+}
+
+// bindKey implements keyBinder.
+func (s *splaySchedule) bindKey(key string) error {
+	if s.max > 0 {
+		h := fnv.New64a()
+		_, _ = h.Write([]byte(key))
+		s.offset = time.Duration(h.Sum64() % uint64(s.max))
+	}
+	return bindKey(s.schedule, key)
+}
@@ -0,0 +1,115 @@
+package cron
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/yu31/cron-go/pkg/expr"
+)
+
+func TestWithJitter(t *testing.T) {
+	begin := time.Unix(662688000, 0)
+	max := time.Minute * 10
+	schedule := WithJitter(&Interval{Interval: time.Hour}, max)
+
+	var jittered bool
+	next := begin
+	for i := 1; i <= 100; i++ {
+		next = schedule.Next(next)
+		planned := begin.Add(time.Hour * time.Duration(i))
+		require.False(t, next.Before(planned))
+		require.True(t, next.Before(planned.Add(max)))
+		jittered = jittered || !next.Equal(planned)
+	}
+	require.True(t, jittered)
+
+	require.Nil(t, schedule.(Validator).Validate())
+	require.NotNil(t, WithJitter(&Interval{Interval: time.Hour}, 0).(Validator).Validate())
+	require.NotNil(t, WithJitter(&Interval{}, max).(Validator).Validate())
+}
+
+func TestWithSplay(t *testing.T) {
+	begin := time.Date(1991, 1, 1, 0, 0, 0, 0, time.Local)
+	max := time.Minute * 10
+
+	offset := func(key string) time.Duration {
+		schedule := WithSplay(&UnixCron{Express: "@hourly"}, max)
+		require.Nil(t, bindKey(schedule, key))
+
+		next := begin
+		var offset time.Duration
+		for i := 1; i <= 5; i++ {
+			next = schedule.Next(next)
+			// The begin is on the hour, so it's the first planned time.
+			planned := begin.Add(time.Hour * time.Duration(i-1))
+			if i == 1 {
+				offset = next.Sub(planned)
+			}
+			require.Equal(t, offset, next.Sub(planned))
+		}
+		require.True(t, offset >= 0 && offset < max)
+		return offset
+	}
+	require.Equal(t, offset("job1"), offset("job1"))
+	require.NotEqual(t, offset("job1"), offset("job2"))
+
+	require.NotNil(t, WithSplay(&Interval{Interval: time.Hour}, 0).(Validator).Validate())
+}
+
+func TestCrontab_SubmitHashed(t *testing.T) {
+	cron := New()
+	require.Nil(t, cron.Start())
+	defer cron.Stop()
+
+	job := JobFunc(func(ctx context.Context) error { return nil })
+	schedule := &UnixCron{Express: "H H * * *"}
+	require.Nil(t, cron.Submit(context.Background(), "job1", job, schedule))
+	require.Equal(t, "job1", schedule.HashKey)
+
+	expected, err := expr.Standard.ParseWithKey("H H * * *", "job1")
+	require.Nil(t, err)
+	info, ok := cron.Get("job1")
+	require.True(t, ok)
+	require.Equal(t, expected.Next(info.Submitted), info.Next)
+
+	// The schedule bound to job1 cannot be submitted with another key, while
+	// it can be submitted again with the same key.
+	require.NotNil(t, cron.Submit(context.Background(), "job3", job, schedule))
+	require.NotNil(t, cron.Submit(context.Background(), "job3", job, WithJitter(schedule, time.Minute)))
+	require.False(t, cron.Has("job3"))
+	require.Equal(t, "job1", schedule.HashKey)
+	require.Nil(t, cron.Submit(context.Background(), "job1", job, schedule))
+
+	// The HashKey set explicitly is kept.
+	schedule = &UnixCron{Express: "H H * * *", HashKey: "shared"}
+	require.Nil(t, cron.Submit(context.Background(), "job2", job, WithSplay(schedule, time.Minute)))
+	require.Equal(t, "shared", schedule.HashKey)
+	require.Nil(t, cron.Submit(context.Background(), "job3", job, schedule))
+}
+
+func TestCrontab_SubmitHashedParsed(t *testing.T) {
+	cron := New()
+	require.Nil(t, cron.Start())
+	defer cron.Stop()
+
+	// The schedules parsed before submitted are resolved with their job keys.
+	job := JobFunc(func(ctx context.Context) error { return nil })
+	var minutes []int
+	for _, key := range []string{"job1", "job2"} {
+		schedule, err := ParseUnixCron("H * * * *")
+		require.Nil(t, err)
+		require.Nil(t, cron.Submit(context.Background(), key, job, schedule))
+		require.Equal(t, key, schedule.HashKey)
+
+		expected, err := expr.Standard.ParseWithKey("H * * * *", key)
+		require.Nil(t, err)
+		info, ok := cron.Get(key)
+		require.True(t, ok)
+		require.Equal(t, expected.Next(info.Submitted), info.Next)
+		minutes = append(minutes, info.Next.Minute())
+	}
+	require.NotEqual(t, minutes[0], minutes[1])
+}
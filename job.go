@@ -2,18 +2,26 @@ package cron
 
 import (
 	"context"
-
-	"github.com/yu31/timewheel-go"
 )
 
 var (
 	_ Job = (*Task)(nil)
 )
 
-type JobFunc = timewheel.JobFunc
+// JobFunc is an adapter to allow the use of ordinary functions as Job.
+type JobFunc func(ctx context.Context) error
+
+// Run implements Job.
+func (f JobFunc) Run(ctx context.Context) error {
+	return f(ctx)
+}
 
+// Job is the task run by Crontab on its Schedule.
 type Job interface {
-	timewheel.Job
+	// Run runs the job, the ctx is canceled when the Crontab is shutdown or the
+	// ctx passed to Submit is done. The running job is not canceled when the job
+	// is removed or replaced.
+	Run(ctx context.Context) error
 }
 
 // Task represents a Job implementation.
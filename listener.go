@@ -0,0 +1,108 @@
+package cron
+
+import "time"
+
+var (
+	_ Listener = (ListenerFunc)(nil)
+)
+
+// EventType is the type of Event.
+type EventType int
+
+const (
+	// EventSubmitted is emitted when a new job is submitted.
+	EventSubmitted EventType = iota + 1
+
+	// EventReplaced is emitted when a job is submitted with the key of an existing job.
+	EventReplaced
+
+	// EventRemoved is emitted when a job is removed.
+	EventRemoved
+
+	// EventScheduled is emitted when the next run of a job is scheduled.
+	// The Event.Time is the planned time of the next run.
+	EventScheduled
+
+	// EventRunStarted is emitted before a job is run. It is not emitted for
+	// the runs skipped by the JobWrapper, e.g. WrapJobSkipIfRunning.
+	EventRunStarted
+
+	// EventRunFinished is emitted after a job is run.
+	// The Event.Duration and Event.Err are the duration and the result of the run.
+	EventRunFinished
+
+	// EventPanic is emitted each time a job panics and recovered by WrapJobRecover,
+	// even if the run is retried and succeeds.
+	// The Event.Err is a *PanicError.
+	EventPanic
+
+	// EventExhausted is emitted when the Schedule of a job returns the zero time,
+	// and the job will not be run anymore.
+	EventExhausted
+
+	// EventRunSkipped is emitted instead of EventRunFinished when the run is skipped
+	// by the JobWrapper, e.g. WrapJobSkipIfRunning, or dropped by the worker pool.
+	EventRunSkipped
+
+	// EventRunDelayed is emitted when the run is delayed by the JobWrapper before
+	// started, e.g. waiting for a slot of WrapJobSemaphore.
+	// The Event.Duration is the duration of the delay.
+	EventRunDelayed
+)
+
+var eventTypeNames = map[EventType]string{
+	EventSubmitted:   "submitted",
+	EventReplaced:    "replaced",
+	EventRemoved:     "removed",
+	EventScheduled:   "scheduled",
+	EventRunStarted:  "run_started",
+	EventRunFinished: "run_finished",
+	EventPanic:       "panic",
+	EventExhausted:   "exhausted",
+	EventRunSkipped:  "run_skipped",
+	EventRunDelayed:  "run_delayed",
+}
+
+func (t EventType) String() string {
+	if name, ok := eventTypeNames[t]; ok {
+		return name
+	}
+	return "unknown"
+}
+
+// Event represents a change in the lifecycle of a job.
+type Event struct {
+	// Type is the type of event.
+	Type EventType
+
+	// Key is the key of job.
+	Key string
+
+	// Time is the planned time of the run. Zero for the events not about a run.
+	Time time.Time
+
+	// Duration is the duration of the run, only set for EventRunFinished,
+	// the duration of the delay for EventRunDelayed, or the lag of the start
+	// behind the planned time for EventRunStarted. The lag is measured by the
+	// Clock of Crontab, from the time of catch-up for the missed runs.
+	Duration time.Duration
+
+	// Err is the error of the run, only set for EventRunFinished and EventPanic.
+	Err error
+}
+
+// Listener receives the events of job lifecycle from the Crontab.
+//
+// The OnEvent is called synchronously in the goroutine that changes the job,
+// e.g. Submit or the running job. Thus, it must not block. It is called without
+// the lock of Crontab held, so it can read the jobs by Get, Has and Jobs.
+type Listener interface {
+	OnEvent(event Event)
+}
+
+// ListenerFunc is an adapter to allow the use of ordinary functions as Listener.
+type ListenerFunc func(event Event)
+
+func (f ListenerFunc) OnEvent(event Event) {
+	f(event)
+}
@@ -0,0 +1,83 @@
+package cron
+
+import (
+	"context"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+var (
+	_ Locker = (*MemoryLocker)(nil)
+)
+
+// Locker used to run each job on exactly one instance when multiple Crontab
+// with the same jobs are running, e.g. the replicas of a service.
+//
+// The Crontab consults the Locker before each scheduled run, and the job is run
+// only on the instance that acquires the lease.
+type Locker interface {
+	// Lock tries to acquire the lease of the run identified by the job key and the
+	// planned time. The lease expires after ttl. It returns true if acquired.
+	Lock(ctx context.Context, key string, planned time.Time, ttl time.Duration) (bool, error)
+}
+
+// LockHooks are called with the outcome of Locker before each scheduled run.
+type LockHooks struct {
+	// OnAcquired is called if the lease acquired, and the job will be run.
+	OnAcquired func(key string, planned time.Time)
+
+	// OnSkipped is called if the lease not acquired, and the run is skipped.
+	// The err is not nil if Locker failed.
+	OnSkipped func(key string, planned time.Time, err error)
+}
+
+// unaligned reports whether the planned times of schedule differ between
+// instances, so the Locker cannot deduplicate its runs. The Interval and
+// "@every" plan the times from the submitted time, and WithJitter randomizes them.
+func unaligned(schedule Schedule) bool {
+	switch s := schedule.(type) {
+	case *Interval, *jitterSchedule:
+		return true
+	case *UnixCron:
+		return strings.Contains(s.Express, "@every")
+	case *splaySchedule:
+		return unaligned(s.schedule)
+	}
+	return false
+}
+
+// MemoryLocker is an in-process Locker, it is usually used to share leases
+// between Crontab in the same process, e.g. in tests.
+type MemoryLocker struct {
+	mu     *sync.Mutex
+	leases map[string]time.Time // the expiration of leases.
+}
+
+// NewMemoryLocker creates a MemoryLocker.
+func NewMemoryLocker() *MemoryLocker {
+	return &MemoryLocker{
+		mu:     new(sync.Mutex),
+		leases: make(map[string]time.Time),
+	}
+}
+
+func (l *MemoryLocker) Lock(_ context.Context, key string, planned time.Time, ttl time.Duration) (bool, error) {
+	now := time.Now()
+	lease := key + "@" + strconv.FormatInt(planned.UnixNano(), 10)
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if expiration, ok := l.leases[lease]; ok && expiration.After(now) {
+		return false, nil
+	}
+	// Deletes the expired leases.
+	for k, expiration := range l.leases {
+		if !expiration.After(now) {
+			delete(l.leases, k)
+		}
+	}
+	l.leases[lease] = now.Add(ttl)
+	return true, nil
+}
@@ -0,0 +1,88 @@
+//go:build !windows
+// +build !windows
+
+package cron
+
+import (
+	"context"
+	"crypto/sha256"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+)
+
+var (
+	_ Locker = (*FileLocker)(nil)
+)
+
+// FileLocker is a Locker based on flock(2), it shares leases between processes
+// on the same machine.
+//
+// Each job has a lock file in the directory, which holds the planned time and
+// the expiration of each unexpired lease, so that a late instance cannot acquire
+// the run of an earlier planned time again.
+type FileLocker struct {
+	dir string
+}
+
+// NewFileLocker creates a FileLocker with the directory of lock files.
+// The directory must exist.
+func NewFileLocker(dir string) *FileLocker {
+	return &FileLocker{dir: dir}
+}
+
+func (l *FileLocker) Lock(_ context.Context, key string, planned time.Time, ttl time.Duration) (bool, error) {
+	name := filepath.Join(l.dir, fmt.Sprintf("%x.lock", sha256.Sum256([]byte(key))))
+	file, err := os.OpenFile(name, os.O_RDWR|os.O_CREATE, 0644)
+	if err != nil {
+		return false, fmt.Errorf("cron: open lock file: %w", err)
+	}
+	defer file.Close()
+
+	if err = syscall.Flock(int(file.Fd()), syscall.LOCK_EX); err != nil {
+		return false, fmt.Errorf("cron: flock: %w", err)
+	}
+	defer func() { _ = syscall.Flock(int(file.Fd()), syscall.LOCK_UN) }()
+
+	data, err := ioutil.ReadAll(file)
+	if err != nil {
+		return false, fmt.Errorf("cron: read lock file: %w", err)
+	}
+
+	now := time.Now()
+	// Each line is "<planned> <expiration>" in unix nanoseconds, the expired
+	// leases are dropped.
+	var content strings.Builder
+	for _, line := range strings.Split(string(data), "\n") {
+		fields := strings.Fields(line)
+		if len(fields) != 2 {
+			continue
+		}
+		p, err1 := strconv.ParseInt(fields[0], 10, 64)
+		e, err2 := strconv.ParseInt(fields[1], 10, 64)
+		if err1 != nil || err2 != nil || e <= now.UnixNano() {
+			continue
+		}
+		if p == planned.UnixNano() {
+			return false, nil
+		}
+		fmt.Fprintf(&content, "%d %d\n", p, e)
+	}
+	fmt.Fprintf(&content, "%d %d\n", planned.UnixNano(), now.Add(ttl).UnixNano())
+
+	if err = file.Truncate(0); err != nil {
+		return false, fmt.Errorf("cron: truncate lock file: %w", err)
+	}
+	if _, err = file.WriteAt([]byte(content.String()), 0); err != nil {
+		return false, fmt.Errorf("cron: write lock file: %w", err)
+	}
+	if err = file.Sync(); err != nil {
+		return false, fmt.Errorf("cron: sync lock file: %w", err)
+	}
+	return true, nil
+}
@@ -0,0 +1,20 @@
+//go:build !windows
+// +build !windows
+
+package cron
+
+import (
+	"io/ioutil"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestFileLocker(t *testing.T) {
+	dir, err := ioutil.TempDir("", "cron-locker")
+	require.Nil(t, err)
+	defer os.RemoveAll(dir)
+
+	testLocker(t, NewFileLocker(dir), NewFileLocker(dir))
+}
@@ -0,0 +1,95 @@
+package cron
+
+import (
+	"context"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func testLocker(t *testing.T, l1, l2 Locker) {
+	ctx := context.Background()
+	planned := time.Now().Truncate(time.Minute)
+
+	ok, err := l1.Lock(ctx, "job1", planned, time.Millisecond*50)
+	require.Nil(t, err)
+	require.True(t, ok)
+	ok, err = l2.Lock(ctx, "job1", planned, time.Millisecond*50)
+	require.Nil(t, err)
+	require.False(t, ok)
+
+	// The other job or the other run.
+	ok, err = l2.Lock(ctx, "job2", planned, time.Millisecond*50)
+	require.Nil(t, err)
+	require.True(t, ok)
+	ok, err = l2.Lock(ctx, "job1", planned.Add(time.Minute), time.Millisecond*50)
+	require.Nil(t, err)
+	require.True(t, ok)
+
+	// The late instance cannot acquire the earlier run again.
+	ok, err = l1.Lock(ctx, "job1", planned, time.Millisecond*50)
+	require.Nil(t, err)
+	require.False(t, ok)
+
+	// The lease expired.
+	ok, err = l1.Lock(ctx, "job2", planned, time.Millisecond*50)
+	require.Nil(t, err)
+	require.False(t, ok)
+	time.Sleep(time.Millisecond * 60)
+	ok, err = l1.Lock(ctx, "job2", planned, time.Millisecond*50)
+	require.Nil(t, err)
+	require.True(t, ok)
+}
+
+func TestMemoryLocker(t *testing.T) {
+	l := NewMemoryLocker()
+	testLocker(t, l, l)
+}
+
+func TestCrontab_Locker(t *testing.T) {
+	locker := NewMemoryLocker()
+	var runs, acquired, skipped int32
+	hooks := LockHooks{
+		OnAcquired: func(key string, planned time.Time) { atomic.AddInt32(&acquired, 1) },
+		OnSkipped:  func(key string, planned time.Time, err error) { atomic.AddInt32(&skipped, 1) },
+	}
+
+	at := time.Now().Add(time.Millisecond * 20)
+	for i := 0; i < 3; i++ {
+		cron := New(WithLocker(locker, time.Minute), WithLockHooks(hooks))
+		require.Nil(t, cron.Start())
+		defer cron.Stop()
+
+		require.Nil(t, cron.Submit(context.Background(), "job1", JobFunc(func(ctx context.Context) error {
+			atomic.AddInt32(&runs, 1)
+			return nil
+		}), &Appoint{Time: at}))
+	}
+
+	require.Eventually(t, func() bool {
+		return atomic.LoadInt32(&acquired)+atomic.LoadInt32(&skipped) == 3
+	}, time.Second, time.Millisecond*10)
+	require.Equal(t, int32(1), atomic.LoadInt32(&acquired))
+	require.Equal(t, int32(2), atomic.LoadInt32(&skipped))
+	require.Equal(t, int32(1), atomic.LoadInt32(&runs))
+}
+
+func TestCrontab_LockerUnaligned(t *testing.T) {
+	logger := new(testLogger)
+	cron := New(WithLocker(NewMemoryLocker(), time.Minute), WithLogger(logger))
+	job := JobFunc(func(ctx context.Context) error { return nil })
+
+	// The planned times of these schedules differ between instances.
+	require.Nil(t, cron.Submit(context.Background(), "job1", job, &Interval{Interval: time.Hour}))
+	require.Nil(t, cron.Submit(context.Background(), "job2", job, &UnixCron{Express: "@every 1h"}))
+	require.Nil(t, cron.Submit(context.Background(), "job3", job, WithJitter(&UnixCron{Express: "@hourly"}, time.Minute)))
+	require.Len(t, logger.lines, 3)
+
+	require.Nil(t, cron.Submit(context.Background(), "job4", job, &UnixCron{Express: "@hourly"}))
+	require.Nil(t, cron.Submit(context.Background(), "job5", job, WithSplay(&UnixCron{Express: "@hourly"}, time.Minute)))
+	require.Len(t, logger.lines, 3)
+	require.True(t, strings.HasPrefix(logger.lines[0], "WARN "))
+}
@@ -0,0 +1,90 @@
+package cron
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"log"
+	"os"
+)
+
+var (
+	_ Logger = (*stdLogger)(nil)
+)
+
+// Logger is the interface used by Crontab and the JobWrapper to log messages.
+//
+// The args are alternating keys and values, same as the log/slog package,
+// thus *slog.Logger can be used as Logger directly.
+type Logger interface {
+	Debug(msg string, args ...interface{})
+	Info(msg string, args ...interface{})
+	Warn(msg string, args ...interface{})
+	Error(msg string, args ...interface{})
+}
+
+var (
+	// DefaultLogger is the Logger used by default, it writes the messages of
+	// level Warn and above to os.Stderr.
+	DefaultLogger Logger = &stdLogger{logger: log.New(os.Stderr, "cron: ", log.LstdFlags), level: levelWarn}
+
+	// DiscardLogger is a Logger that discards all messages.
+	DiscardLogger = NewStdLogger(log.New(ioutil.Discard, "", 0), false)
+)
+
+// The levels of messages written by stdLogger.
+const (
+	levelDebug = iota
+	levelInfo
+	levelWarn
+	levelError
+)
+
+// NewStdLogger creates a Logger that writes the messages to the standard log.Logger
+// in the format "LEVEL msg key1=value1 key2=value2". The Debug messages are
+// discarded unless debug is true.
+func NewStdLogger(logger *log.Logger, debug bool) Logger {
+	level := levelInfo
+	if debug {
+		level = levelDebug
+	}
+	return &stdLogger{logger: logger, level: level}
+}
+
+type stdLogger struct {
+	logger *log.Logger
+	level  int // the lowest level of messages written.
+}
+
+func (l *stdLogger) Debug(msg string, args ...interface{}) {
+	l.output(levelDebug, "DEBUG", msg, args)
+}
+
+func (l *stdLogger) Info(msg string, args ...interface{}) {
+	l.output(levelInfo, "INFO", msg, args)
+}
+
+func (l *stdLogger) Warn(msg string, args ...interface{}) {
+	l.output(levelWarn, "WARN", msg, args)
+}
+
+func (l *stdLogger) Error(msg string, args ...interface{}) {
+	l.output(levelError, "ERROR", msg, args)
+}
+
+func (l *stdLogger) output(level int, name string, msg string, args []interface{}) {
+	if level < l.level {
+		return
+	}
+	buf := bytes.NewBufferString(name)
+	buf.WriteByte(' ')
+	buf.WriteString(msg)
+	for i := 0; i < len(args); i += 2 {
+		if i+1 < len(args) {
+			_, _ = fmt.Fprintf(buf, " %v=%q", args[i], fmt.Sprint(args[i+1]))
+		} else {
+			_, _ = fmt.Fprintf(buf, " !BADKEY=%q", fmt.Sprint(args[i]))
+		}
+	}
+	_ = l.logger.Output(3, buf.String())
+}
@@ -0,0 +1,15 @@
+//go:build go1.21
+// +build go1.21
+
+package cron
+
+import "log/slog"
+
+var (
+	_ Logger = (*slog.Logger)(nil)
+)
+
+// NewSlogLogger returns the *slog.Logger as Logger.
+func NewSlogLogger(logger *slog.Logger) Logger {
+	return logger
+}
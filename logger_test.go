@@ -0,0 +1,70 @@
+package cron
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+type testLogger struct {
+	mu    sync.Mutex
+	lines []string
+}
+
+func (l *testLogger) log(level string, msg string, args []interface{}) {
+	l.mu.Lock()
+	l.lines = append(l.lines, strings.TrimSuffix(fmt.Sprintln(append([]interface{}{level, msg}, args...)...), "\n"))
+	l.mu.Unlock()
+}
+
+func (l *testLogger) Debug(msg string, args ...interface{}) {}
+func (l *testLogger) Info(msg string, args ...interface{})  { l.log("INFO", msg, args) }
+func (l *testLogger) Warn(msg string, args ...interface{})  { l.log("WARN", msg, args) }
+func (l *testLogger) Error(msg string, args ...interface{}) { l.log("ERROR", msg, args) }
+
+func TestStdLogger(t *testing.T) {
+	buf := new(bytes.Buffer)
+	logger := NewStdLogger(log.New(buf, "", 0), false)
+	logger.Debug("debug")
+	logger.Info("job run", "key", "job1", "error", errors.New("failed"), "odd")
+	require.Equal(t, "INFO job run key=\"job1\" error=\"failed\" !BADKEY=\"odd\"\n", buf.String())
+
+	buf.Reset()
+	logger = NewStdLogger(log.New(buf, "", 0), true)
+	logger.Debug("debug")
+	require.Equal(t, "DEBUG debug\n", buf.String())
+	// The DefaultLogger writes only the messages of level Warn and above.
+	buf.Reset()
+	logger = &stdLogger{logger: log.New(buf, "", 0), level: DefaultLogger.(*stdLogger).level}
+	logger.Debug("debug")
+	logger.Info("info")
+	logger.Warn("warn")
+	logger.Error("error")
+	require.Equal(t, "WARN warn\nERROR error\n", buf.String())
+}
+
+func TestWrapJobRecover_Logger(t *testing.T) {
+	logger := new(testLogger)
+	cron := New(WithLogger(logger), WithJobWrapper(WrapJobRecover()))
+	require.Nil(t, cron.Submit(context.Background(), "job1", JobFunc(func(ctx context.Context) error {
+		panic("oops")
+	}), &Interval{Interval: time.Hour}))
+
+	err := cron.RunNow(context.Background(), "job1")
+	var panicErr *PanicError
+	require.True(t, errors.As(err, &panicErr))
+	// Only the stack of the panicking goroutine.
+	require.True(t, strings.HasPrefix(string(panicErr.Stack), "goroutine "))
+	require.False(t, strings.Contains(string(panicErr.Stack), "\n\ngoroutine "))
+
+	require.Len(t, logger.lines, 1)
+	require.True(t, strings.HasPrefix(logger.lines[0], "ERROR job run panic key job1 error cron: job run panic with error: oops stack goroutine "))
+}
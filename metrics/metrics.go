@@ -0,0 +1,119 @@
+// Package metrics provides the metrics of jobs run by cron.Crontab.
+//
+// The measurements are reported to a Collector by the cron.Listener returned by
+// NewListener. The Registry is a dependency-free Collector that exposes the metrics
+// in the Prometheus text format:
+//
+//	registry := metrics.NewRegistry()
+//	crontab := cron.New(cron.WithListener(metrics.NewListener(registry)))
+//	http.Handle("/metrics", registry)
+package metrics
+
+import (
+	"sync"
+	"time"
+
+	"github.com/yu31/cron-go"
+)
+
+// Collector receives the measurements of jobs. The key is the key of job.
+type Collector interface {
+	// RunStarted is called when a job run is started.
+	RunStarted(key string)
+
+	// RunSucceeded is called when a job run is completed without error.
+	RunSucceeded(key string)
+
+	// RunFailed is called when a job run is completed with error, including panics.
+	RunFailed(key string)
+
+	// RunPanicked is called when a job run panics and recovered by cron.WrapJobRecover.
+	RunPanicked(key string)
+
+	// RunSkipped is called when a job run is skipped, e.g. by cron.WrapJobSkipIfRunning.
+	RunSkipped(key string)
+
+	// ObserveDuration observes the duration of a completed job run.
+	ObserveDuration(key string, d time.Duration)
+
+	// ObserveLag observes the lag of a job run, i.e. the actual start time minus the planned time.
+	// The lag of a missed run is measured from the time it is caught up, see cron.Event.Duration.
+	ObserveLag(key string, d time.Duration)
+
+	// SetJobs sets the number of jobs registered in the Crontab.
+	SetJobs(n int)
+
+	// RemoveJob drops the measurements of a job, it is called when the job is
+	// removed or its schedule is exhausted.
+	RemoveJob(key string)
+}
+
+// NewListener returns a cron.Listener that reports the measurements of events to the collector.
+func NewListener(collector Collector) cron.Listener {
+	return &listener{
+		collector: collector,
+		mu:        new(sync.Mutex),
+		jobs:      make(map[string]struct{}),
+		exhausted: make(map[string]struct{}),
+	}
+}
+
+type listener struct {
+	collector Collector
+
+	mu        *sync.Mutex
+	jobs      map[string]struct{} // the key of jobs registered.
+	exhausted map[string]struct{} // the key of jobs exhausted, whose last run may be not completed.
+}
+
+func (l *listener) OnEvent(event cron.Event) {
+	c := l.collector
+	switch event.Type {
+	case cron.EventSubmitted, cron.EventReplaced:
+		l.mu.Lock()
+		l.jobs[event.Key] = struct{}{}
+		delete(l.exhausted, event.Key)
+		c.SetJobs(len(l.jobs))
+		l.mu.Unlock()
+	case cron.EventRemoved:
+		l.mu.Lock()
+		delete(l.jobs, event.Key)
+		delete(l.exhausted, event.Key)
+		c.SetJobs(len(l.jobs))
+		c.RemoveJob(event.Key)
+		l.mu.Unlock()
+	case cron.EventExhausted:
+		// The last run is fired after the event, its measurements are dropped
+		// again once it completed.
+		l.mu.Lock()
+		l.exhausted[event.Key] = struct{}{}
+		c.RemoveJob(event.Key)
+		l.mu.Unlock()
+	case cron.EventRunStarted:
+		c.RunStarted(event.Key)
+		c.ObserveLag(event.Key, event.Duration)
+	case cron.EventRunFinished:
+		if event.Err != nil {
+			c.RunFailed(event.Key)
+		} else {
+			c.RunSucceeded(event.Key)
+		}
+		c.ObserveDuration(event.Key, event.Duration)
+		l.completed(event.Key)
+	case cron.EventPanic:
+		c.RunPanicked(event.Key)
+	case cron.EventRunSkipped:
+		c.RunSkipped(event.Key)
+		l.completed(event.Key)
+	}
+}
+
+// completed drops the measurements of the exhausted job after its last run.
+func (l *listener) completed(key string) {
+	l.mu.Lock()
+	if _, ok := l.exhausted[key]; ok {
+		delete(l.exhausted, key)
+		l.collector.RemoveJob(key)
+	}
+	l.mu.Unlock()
+}
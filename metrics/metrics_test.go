@@ -0,0 +1,120 @@
+package metrics
+
+import (
+	"context"
+	"errors"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/yu31/cron-go"
+)
+
+func TestRegistry(t *testing.T) {
+	registry := NewRegistry(0.1, 1)
+	crontab := cron.New(
+		cron.WithListener(NewListener(registry)),
+		cron.WithLogger(cron.DiscardLogger),
+		cron.WithJobWrapper(cron.WrapJobRecover(), cron.WrapJobSkipIfRunning()),
+	)
+
+	startC := make(chan struct{})
+	doneC := make(chan struct{})
+	require.Nil(t, crontab.Submit(context.Background(), "job1", cron.JobFunc(func(ctx context.Context) error {
+		close(startC)
+		<-doneC
+		return nil
+	}), &cron.Interval{Interval: time.Hour}))
+	require.Nil(t, crontab.Submit(context.Background(), "job\"2", cron.JobFunc(func(ctx context.Context) error {
+		return errors.New("failed")
+	}), &cron.Interval{Interval: time.Hour}))
+	require.Nil(t, crontab.Submit(context.Background(), "job3", cron.JobFunc(func(ctx context.Context) error {
+		panic("oops")
+	}), &cron.Interval{Interval: time.Hour}))
+	require.Nil(t, crontab.Submit(context.Background(), "job4", cron.JobFunc(func(ctx context.Context) error {
+		return nil
+	}), &cron.Interval{Interval: time.Hour}))
+	crontab.Remove("job4")
+
+	go func() { _ = crontab.RunNow(context.Background(), "job1") }()
+	<-startC
+	require.Equal(t, cron.ErrJobSkipped, crontab.RunNow(context.Background(), "job1"))
+	close(doneC)
+	require.NotNil(t, crontab.RunNow(context.Background(), "job\"2"))
+	require.NotNil(t, crontab.RunNow(context.Background(), "job3"))
+
+	require.Eventually(t, func() bool {
+		w := httptest.NewRecorder()
+		registry.ServeHTTP(w, httptest.NewRequest("GET", "/metrics", nil))
+		return strings.Contains(w.Body.String(), `cron_job_runs_succeeded_total{key="job1"} 1`)
+	}, time.Second, time.Millisecond*10)
+
+	w := httptest.NewRecorder()
+	registry.ServeHTTP(w, httptest.NewRequest("GET", "/metrics", nil))
+	require.Equal(t, "text/plain; version=0.0.4; charset=utf-8", w.Header().Get("Content-Type"))
+
+	body := w.Body.String()
+	for _, line := range []string{
+		"# TYPE cron_job_runs_started_total counter",
+		`cron_job_runs_started_total{key="job1"} 1`,
+		`cron_job_runs_started_total{key="job\"2"} 1`,
+		`cron_job_runs_skipped_total{key="job1"} 1`,
+		`cron_job_runs_failed_total{key="job\"2"} 1`,
+		`cron_job_runs_failed_total{key="job3"} 1`,
+		`cron_job_runs_panicked_total{key="job3"} 1`,
+		"# TYPE cron_job_run_duration_seconds histogram",
+		`cron_job_run_duration_seconds_bucket{key="job3",le="0.1"} 1`,
+		`cron_job_run_duration_seconds_bucket{key="job3",le="+Inf"} 1`,
+		`cron_job_run_duration_seconds_count{key="job1"} 1`,
+		`cron_job_schedule_lag_seconds_count{key="job1"} 1`,
+		"# TYPE cron_jobs gauge",
+		"cron_jobs 3",
+	} {
+		require.Contains(t, body, line+"\n")
+	}
+}
+
+func TestRegistry_RemoveJob(t *testing.T) {
+	registry := NewRegistry(1, 60)
+	crontab := cron.New(cron.WithListener(NewListener(registry)), cron.WithLogger(cron.DiscardLogger))
+	require.Nil(t, crontab.Start())
+	defer crontab.Stop()
+
+	metrics := func() string {
+		w := httptest.NewRecorder()
+		registry.ServeHTTP(w, httptest.NewRequest("GET", "/metrics", nil))
+		return w.Body.String()
+	}
+	runC := make(chan string, 8)
+	job := func(key string) cron.Job {
+		return cron.JobFunc(func(ctx context.Context) error {
+			runC <- key
+			return nil
+		})
+	}
+
+	// The lag of the missed run is measured from the time it is caught up.
+	lastRun := time.Now().Add(-time.Hour * 3)
+	require.Nil(t, crontab.Submit(context.Background(), "job1", job("job1"), &cron.Interval{Interval: time.Hour},
+		cron.WithMisfire(cron.MisfireRunOnce, lastRun)))
+	require.Equal(t, "job1", <-runC)
+	require.Eventually(t, func() bool {
+		return strings.Contains(metrics(), `cron_job_runs_succeeded_total{key="job1"} 1`)
+	}, time.Second, time.Millisecond*10)
+	require.Contains(t, metrics(), `cron_job_schedule_lag_seconds_bucket{key="job1",le="1"} 1`+"\n")
+
+	// The measurements of the removed job are dropped.
+	crontab.Remove("job1")
+	require.NotContains(t, metrics(), `key="job1"`)
+
+	// The measurements of the exhausted job are dropped after its last run.
+	require.Nil(t, crontab.Submit(context.Background(), "job2", job("job2"), &cron.Appoint{Time: time.Now().Add(time.Millisecond * 10)}))
+	require.Equal(t, "job2", <-runC)
+	require.Eventually(t, func() bool {
+		body := metrics()
+		return !strings.Contains(body, `key="job2"`) && strings.Contains(body, "cron_jobs 1\n")
+	}, time.Second, time.Millisecond*10)
+}
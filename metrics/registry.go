@@ -0,0 +1,211 @@
+package metrics
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+var (
+	_ Collector    = (*Registry)(nil)
+	_ http.Handler = (*Registry)(nil)
+)
+
+// DefaultBuckets is the default upper bounds of histogram buckets, in seconds.
+var DefaultBuckets = []float64{.005, .01, .025, .05, .1, .25, .5, 1, 2.5, 5, 10, 30, 60, 300}
+
+// The names of metrics exposed by Registry.
+const (
+	nameRunsStarted   = "cron_job_runs_started_total"
+	nameRunsSucceeded = "cron_job_runs_succeeded_total"
+	nameRunsFailed    = "cron_job_runs_failed_total"
+	nameRunsPanicked  = "cron_job_runs_panicked_total"
+	nameRunsSkipped   = "cron_job_runs_skipped_total"
+	nameRunDuration   = "cron_job_run_duration_seconds"
+	nameScheduleLag   = "cron_job_schedule_lag_seconds"
+	nameJobs          = "cron_jobs"
+)
+
+var counterHelps = []struct{ name, help string }{
+	{nameRunsStarted, "Total number of job runs started."},
+	{nameRunsSucceeded, "Total number of job runs completed without error."},
+	{nameRunsFailed, "Total number of job runs completed with error."},
+	{nameRunsPanicked, "Total number of job runs panicked."},
+	{nameRunsSkipped, "Total number of job runs skipped."},
+}
+
+// Registry is a Collector that keeps the metrics in memory, and exposes them
+// in the Prometheus text format by ServeHTTP.
+type Registry struct {
+	mu       *sync.Mutex
+	buckets  []float64
+	counters map[string]map[string]uint64 // name => key => value.
+	duration map[string]*histogram
+	lag      map[string]*histogram
+	jobs     int
+}
+
+// NewRegistry creates a Registry with the upper bounds of histogram buckets in seconds.
+// The DefaultBuckets is used if no buckets is given.
+func NewRegistry(buckets ...float64) *Registry {
+	if len(buckets) == 0 {
+		buckets = DefaultBuckets
+	}
+	buckets = append([]float64(nil), buckets...)
+	sort.Float64s(buckets)
+
+	counters := make(map[string]map[string]uint64, len(counterHelps))
+	for _, c := range counterHelps {
+		counters[c.name] = make(map[string]uint64)
+	}
+	return &Registry{
+		mu:       new(sync.Mutex),
+		buckets:  buckets,
+		counters: counters,
+		duration: make(map[string]*histogram),
+		lag:      make(map[string]*histogram),
+		jobs:     0,
+	}
+}
+
+func (r *Registry) inc(name string, key string) {
+	r.mu.Lock()
+	r.counters[name][key]++
+	r.mu.Unlock()
+}
+
+func (r *Registry) observe(histograms map[string]*histogram, key string, d time.Duration) {
+	r.mu.Lock()
+	h, ok := histograms[key]
+	if !ok {
+		h = &histogram{counts: make([]uint64, len(r.buckets))}
+		histograms[key] = h
+	}
+	h.observe(r.buckets, d.Seconds())
+	r.mu.Unlock()
+}
+
+func (r *Registry) RunStarted(key string)   { r.inc(nameRunsStarted, key) }
+func (r *Registry) RunSucceeded(key string) { r.inc(nameRunsSucceeded, key) }
+func (r *Registry) RunFailed(key string)    { r.inc(nameRunsFailed, key) }
+func (r *Registry) RunPanicked(key string)  { r.inc(nameRunsPanicked, key) }
+func (r *Registry) RunSkipped(key string)   { r.inc(nameRunsSkipped, key) }
+
+func (r *Registry) ObserveDuration(key string, d time.Duration) { r.observe(r.duration, key, d) }
+func (r *Registry) ObserveLag(key string, d time.Duration)      { r.observe(r.lag, key, d) }
+
+func (r *Registry) SetJobs(n int) {
+	r.mu.Lock()
+	r.jobs = n
+	r.mu.Unlock()
+}
+
+func (r *Registry) RemoveJob(key string) {
+	r.mu.Lock()
+	for _, values := range r.counters {
+		delete(values, key)
+	}
+	delete(r.duration, key)
+	delete(r.lag, key)
+	r.mu.Unlock()
+}
+
+// ServeHTTP writes all metrics in the Prometheus text format.
+func (r *Registry) ServeHTTP(w http.ResponseWriter, _ *http.Request) {
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+	_ = r.WriteText(w)
+}
+
+// WriteText writes all metrics in the Prometheus text format to w.
+func (r *Registry) WriteText(out io.Writer) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	w := bufio.NewWriter(out)
+
+	for _, c := range counterHelps {
+		writeHeader(w, c.name, c.help, "counter")
+		values := r.counters[c.name]
+		for _, key := range sortedKeys(values) {
+			_, _ = fmt.Fprintf(w, "%s{key=\"%s\"} %d\n", c.name, escape(key), values[key])
+		}
+	}
+
+	r.writeHistograms(w, nameRunDuration, "Duration of job runs in seconds.", r.duration)
+	r.writeHistograms(w, nameScheduleLag, "Lag between the actual start and the planned time of job runs in seconds.", r.lag)
+
+	writeHeader(w, nameJobs, "Number of jobs registered.", "gauge")
+	_, _ = fmt.Fprintf(w, "%s %d\n", nameJobs, r.jobs)
+	return w.Flush()
+}
+
+func (r *Registry) writeHistograms(w *bufio.Writer, name string, help string, histograms map[string]*histogram) {
+	writeHeader(w, name, help, "histogram")
+
+	keys := make([]string, 0, len(histograms))
+	for key := range histograms {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		h := histograms[key]
+		label := escape(key)
+		cumulative := uint64(0)
+		for i, bound := range r.buckets {
+			cumulative += h.counts[i]
+			_, _ = fmt.Fprintf(w, "%s_bucket{key=\"%s\",le=\"%s\"} %d\n", name, label, formatFloat(bound), cumulative)
+		}
+		_, _ = fmt.Fprintf(w, "%s_bucket{key=\"%s\",le=\"+Inf\"} %d\n", name, label, h.count)
+		_, _ = fmt.Fprintf(w, "%s_sum{key=\"%s\"} %s\n", name, label, formatFloat(h.sum))
+		_, _ = fmt.Fprintf(w, "%s_count{key=\"%s\"} %d\n", name, label, h.count)
+	}
+}
+
+// histogram counts the observations in buckets, the counts are not cumulative.
+type histogram struct {
+	counts []uint64
+	sum    float64
+	count  uint64
+}
+
+func (h *histogram) observe(buckets []float64, v float64) {
+	for i, bound := range buckets {
+		if v <= bound {
+			h.counts[i]++
+			break
+		}
+	}
+	h.sum += v
+	h.count++
+}
+
+func writeHeader(w *bufio.Writer, name string, help string, typ string) {
+	_, _ = fmt.Fprintf(w, "# HELP %s %s\n# TYPE %s %s\n", name, help, name, typ)
+}
+
+func sortedKeys(values map[string]uint64) []string {
+	keys := make([]string, 0, len(values))
+	for key := range values {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+var labelEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+
+// escape escapes the label value.
+func escape(s string) string {
+	return labelEscaper.Replace(s)
+}
+
+func formatFloat(f float64) string {
+	return strconv.FormatFloat(f, 'g', -1, 64)
+}
@@ -0,0 +1,55 @@
+package cron
+
+import "time"
+
+// MisfirePolicy decides how to handle the runs that have been missed,
+// e.g. the runs that were planned while the job was paused.
+type MisfirePolicy int
+
+const (
+	// MisfireSkip skips all missed runs.
+	MisfireSkip MisfirePolicy = iota
+
+	// MisfireRunOnce runs the job once immediately if any runs were missed,
+	// with the planned time of the latest missed run.
+	MisfireRunOnce
+
+	// MisfireRunAll runs the job for each missed run in order.
+	// The number of runs is limited by WithMaxBacklog, DefaultMaxBacklog by default.
+	MisfireRunAll
+)
+
+// DefaultMaxBacklog is the max number of missed runs with MisfireRunAll if no
+// WithMaxBacklog set.
+const DefaultMaxBacklog = 100
+
+// misfire returns the planned time of missed runs that should be run by the policy,
+// and the next run after now. The first is the first planned run of the schedule.
+//
+// The backlog limits the number of missed runs with MisfireRunAll, the earliest
+// runs are kept. The backlog <= 0 means DefaultMaxBacklog.
+func misfire(schedule Schedule, first time.Time, now time.Time, policy MisfirePolicy, backlog int) (missed []time.Time, next time.Time) {
+	if first.IsZero() || first.After(now) {
+		return nil, first
+	}
+	if policy == MisfireSkip {
+		return nil, schedule.Next(now)
+	}
+	if policy == MisfireRunOnce {
+		latest := first
+		for next = schedule.Next(first); !next.IsZero() && !next.After(now); next = schedule.Next(next) {
+			latest = next
+		}
+		return []time.Time{latest}, next
+	}
+	if backlog <= 0 {
+		backlog = DefaultMaxBacklog
+	}
+	for next = first; !next.IsZero() && !next.After(now); next = schedule.Next(next) {
+		missed = append(missed, next)
+		if len(missed) >= backlog {
+			return missed, schedule.Next(now)
+		}
+	}
+	return missed, next
+}
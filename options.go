@@ -12,6 +12,23 @@ func WithTimezone(loc *time.Location) Option {
 	}
 }
 
+// WithClock sets the Clock that Crontab schedules the jobs with. The default is
+// the system clock. It is mostly used in tests, see the FakeClock in package crontest.
+// The activations are fired by the Clock unless a Driver is set with WithDriver.
+func WithClock(clock Clock) Option {
+	return func(cron *Crontab) {
+		cron.clock = clock
+	}
+}
+
+// WithDriver sets the Driver that fires the activations of jobs. The default is
+// the timewheel driver returned by NewTimeWheelDriver.
+func WithDriver(driver Driver) Option {
+	return func(cron *Crontab) {
+		cron.driver = driver
+	}
+}
+
 // WithJobWrapper append JobWrapper into jobChain
 func WithJobWrapper(w ...JobWrapper) Option {
 	return func(cron *Crontab) {
@@ -25,3 +42,161 @@ func WithJobChain(jobChain JobChain) Option {
 		cron.jobChain = jobChain
 	}
 }
+
+// WithResumeMisfire sets the MisfirePolicy to handle the runs that were missed while
+// the job was paused. The default is MisfireSkip.
+func WithResumeMisfire(policy MisfirePolicy) Option {
+	return func(cron *Crontab) {
+		cron.resumeMisfire = policy
+	}
+}
+
+// WithStore sets the Store to save the persistent jobs submitted by SubmitPersistent.
+// The jobs are reloaded from store on Start, and recreated by the JobFactory in registry.
+func WithStore(store Store, registry *Registry) Option {
+	return func(cron *Crontab) {
+		cron.store = store
+		cron.registry = registry
+	}
+}
+
+// WithLocker sets the Locker to run each job on exactly one instance. The lease of
+// each run expires after ttl, which should be longer than the clock skew of instances.
+//
+// The runs are deduplicated by the planned time, so the schedule must plan the same
+// times on all instances, e.g. UnixCron and Appoint. The Interval and the "@every"
+// descriptor plan the times from the submitted time of each instance, and WithJitter
+// randomizes the times on each instance, so the Locker does not deduplicate their
+// runs, a warning is logged when they are submitted.
+func WithLocker(locker Locker, ttl time.Duration) Option {
+	return func(cron *Crontab) {
+		cron.locker = locker
+		cron.lockTTL = ttl
+	}
+}
+
+// WithLockHooks sets the LockHooks called with the outcome of Locker.
+func WithLockHooks(hooks LockHooks) Option {
+	return func(cron *Crontab) {
+		cron.lockHooks = hooks
+	}
+}
+
+// WithListener appends the Listener to receive the events of job lifecycle.
+func WithListener(listener ...Listener) Option {
+	return func(cron *Crontab) {
+		cron.listeners = append(cron.listeners, listener...)
+	}
+}
+
+// WithLogger sets the Logger used by Crontab and the JobWrapper. The default is DefaultLogger.
+func WithLogger(logger Logger) Option {
+	return func(cron *Crontab) {
+		cron.logger = logger
+	}
+}
+
+// WithConcurrencyGroup adds a named group that allows at most limit jobs in the
+// group running at once, the jobs are added to the group by WithGroup when submitted.
+// The timeout is the max time a job waits for a slot, see NewSemaphore.
+func WithConcurrencyGroup(name string, limit int, timeout time.Duration) Option {
+	return func(cron *Crontab) {
+		cron.groups[name] = NewSemaphore(limit, timeout)
+	}
+}
+
+// SubmitOption represents a modification to the job submitted to Crontab.
+type SubmitOption func(opts *submitOptions)
+
+// submitOptions holds the options of the job submitted.
+type submitOptions struct {
+	misfire  MisfirePolicy
+	lastRun  time.Time
+	nextRun  time.Time // the first planned time of the reloaded job that never completed a run.
+	backlog  int
+	group    string
+	priority Priority
+}
+
+func newSubmitOptions(opts []SubmitOption) *submitOptions {
+	so := &submitOptions{
+		misfire:  MisfireSkip,
+		lastRun:  time.Time{},
+		nextRun:  time.Time{},
+		backlog:  0,
+		group:    "",
+		priority: PriorityNormal,
+	}
+	for _, opt := range opts {
+		opt(so)
+	}
+	return so
+}
+
+// WithMisfire sets the MisfirePolicy to handle the runs missed since the lastRun,
+// e.g. the runs planned while the process was down. The zero lastRun means the job
+// has never been run, and no runs are missed.
+func WithMisfire(policy MisfirePolicy, lastRun time.Time) SubmitOption {
+	return func(opts *submitOptions) {
+		opts.misfire = policy
+		opts.lastRun = lastRun
+	}
+}
+
+// WithMaxBacklog limits the number of missed runs with MisfireRunAll, the earliest
+// runs are kept. The n <= 0 means DefaultMaxBacklog.
+func WithMaxBacklog(n int) SubmitOption {
+	return func(opts *submitOptions) {
+		opts.backlog = n
+	}
+}
+
+// WithGroup adds the job to the named group set by WithConcurrencyGroup,
+// which limits the jobs in the group running at once.
+func WithGroup(name string) SubmitOption {
+	return func(opts *submitOptions) {
+		opts.group = name
+	}
+}
+
+// WithPriority sets the Priority of the job in the worker pool set with WithWorkerPool,
+// the runs with higher priority are taken from the queue first. The default is PriorityNormal.
+func WithPriority(priority Priority) SubmitOption {
+	return func(opts *submitOptions) {
+		opts.priority = priority
+	}
+}
+
+// WithWorkerPool runs the jobs on a fixed number of workers instead of a new
+// goroutine for each run. The fired runs wait in a queue of queueSize for a free
+// worker, and the runs fired while the queue is full are handled by the
+// OverflowPolicy set with WithOverflowPolicy. The stats of pool are returned by
+// Crontab.PoolStats.
+//
+// The catch-up runs of misfire are queued on the pool like the fired runs, so they
+// can be dropped by OverflowDrop or WithStaleness as well. The RunNow is not run on
+// the pool. Notice that the runs on pool are not waited by the FakeClock of package
+// crontest.
+func WithWorkerPool(workers int, queueSize int) Option {
+	return func(cron *Crontab) {
+		cron.pool = newWorkerPool(workers, queueSize)
+	}
+}
+
+// WithOverflowPolicy sets the OverflowPolicy to handle the runs fired while the
+// queue of worker pool is full. The default is OverflowBlock.
+func WithOverflowPolicy(policy OverflowPolicy) Option {
+	return func(cron *Crontab) {
+		cron.overflow = policy
+	}
+}
+
+// WithStaleness drops the runs with priority lower than PriorityNormal that have
+// waited in the queue of worker pool longer than d, they are reported as
+// EventRunSkipped. The wait is measured by the Clock of Crontab.
+// The d <= 0 means no limited, which is the default.
+func WithStaleness(d time.Duration) Option {
+	return func(cron *Crontab) {
+		cron.staleness = d
+	}
+}
@@ -7,6 +7,12 @@ type bounds struct {
 	names map[string]uint
 }
 
+// The range of year field.
+const (
+	yearMin = 1970
+	yearMax = 2099
+)
+
 // The bounds for each field.
 var (
 	secondBonds  = bounds{0, 59, nil}
@@ -27,7 +33,8 @@ var (
 		"nov": 11,
 		"dec": 12,
 	}}
-	dowBounds = bounds{0, 6, map[string]uint{
+	yearBounds = bounds{yearMin, yearMax, nil}
+	dowBounds  = bounds{0, 6, map[string]uint{
 		"sun": 0,
 		"mon": 1,
 		"tue": 2,
@@ -37,3 +44,20 @@ var (
 		"sat": 6,
 	}}
 )
+
+// hashBounds is the bounds of field and the default range of the hashed token.
+type hashBounds struct {
+	bounds
+	low, high uint
+}
+
+// The hashBounds of each field except the year. The default range of day of
+// month is 1-28 to be valid in every month.
+var hashFields = []hashBounds{
+	{secondBonds, secondBonds.min, secondBonds.max},
+	{minuteBounds, minuteBounds.min, minuteBounds.max},
+	{hourBounds, hourBounds.min, hourBounds.max},
+	{domBounds, domBounds.min, 28},
+	{monthBounds, monthBounds.min, monthBounds.max},
+	{dowBounds, dowBounds.min, dowBounds.max},
+}
@@ -0,0 +1,401 @@
+package expr
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+	"unicode/utf8"
+)
+
+// Phrases is the phrase table used to describe a crontab expression in a
+// natural language. Each phrase is a format of fmt.Sprintf, the verbs are
+// documented with the English phrase.
+//
+// Add a new locale by creating a Phrases with the translated phrases.
+type Phrases struct {
+	Every string // "every %s", the interval of "@every".
+
+	EverySecond  string // "every second"
+	EveryMinute  string // "every minute"
+	EveryHour    string // "every hour"
+	EveryHourTo  string // "every hour from %s through %s", the times.
+	EverySeconds string // "every %s seconds", the step.
+	EveryMinutes string // "every %s minutes", the step.
+	EveryHours   string // "every %s hours", the step.
+	EveryDays    string // "every %s days", the step.
+	EveryMonths  string // "every %s months", the step.
+
+	At          string // "at %s", the list of times.
+	AtSeconds   string // "at %s seconds past the minute", the list of seconds.
+	AtMinutes   string // "at %s minutes past the hour", the list of minutes.
+	AtMinute    string // "at minute %s", the minute of the hours in step.
+	SecondsTo   string // "seconds %s through %s past the minute"
+	MinutesTo   string // "minutes %s through %s past the hour"
+	Between     string // "between %s and %s", the times.
+	DuringHours string // "during hours %s", the list of hours.
+
+	OnDays         string // "on day %s of the month", the list of days.
+	DaysBetween    string // "between day %s and %s of the month"
+	LastDay        string // "on the last day of the month"
+	LastWeekday    string // "on the last weekday of the month"
+	NearestWeekday string // "on the weekday nearest day %s of the month", the list of days.
+	LastDow        string // "on the last %s of the month", the day of week.
+	NthDow         string // "on the %s %s of the month", the ordinal and day of week.
+
+	OnlyOn     string // "only on %s", the list of days of week.
+	OnlyIn     string // "only in %s", the list of months or years.
+	Through    string // "%s through %s"
+	Or         string // "or %s"
+	InLocation string // "in %s time", the name of location.
+
+	Separator   string // ", ", separates the segments and the items of list.
+	Conjunction string // " and ", joins the last item of list.
+
+	Ordinals [5]string  // "first" to "fifth".
+	Weekdays [7]string  // "Sunday" to "Saturday".
+	Months   [12]string // "January" to "December".
+}
+
+// English is the Phrases in English.
+var English = &Phrases{
+	Every: "every %s",
+
+	EverySecond:  "every second",
+	EveryMinute:  "every minute",
+	EveryHour:    "every hour",
+	EveryHourTo:  "every hour from %s through %s",
+	EverySeconds: "every %s seconds",
+	EveryMinutes: "every %s minutes",
+	EveryHours:   "every %s hours",
+	EveryDays:    "every %s days",
+	EveryMonths:  "every %s months",
+
+	At:          "at %s",
+	AtSeconds:   "at %s seconds past the minute",
+	AtMinutes:   "at %s minutes past the hour",
+	AtMinute:    "at minute %s",
+	SecondsTo:   "seconds %s through %s past the minute",
+	MinutesTo:   "minutes %s through %s past the hour",
+	Between:     "between %s and %s",
+	DuringHours: "during hours %s",
+
+	OnDays:         "on day %s of the month",
+	DaysBetween:    "between day %s and %s of the month",
+	LastDay:        "on the last day of the month",
+	LastWeekday:    "on the last weekday of the month",
+	NearestWeekday: "on the weekday nearest day %s of the month",
+	LastDow:        "on the last %s of the month",
+	NthDow:         "on the %s %s of the month",
+
+	OnlyOn:     "only on %s",
+	OnlyIn:     "only in %s",
+	Through:    "%s through %s",
+	Or:         "or %s",
+	InLocation: "in %s time",
+
+	Separator:   ", ",
+	Conjunction: " and ",
+
+	Ordinals: [5]string{"first", "second", "third", "fourth", "fifth"},
+	Weekdays: [7]string{"Sunday", "Monday", "Tuesday", "Wednesday", "Thursday", "Friday", "Saturday"},
+	Months: [12]string{
+		"January", "February", "March", "April", "May", "June",
+		"July", "August", "September", "October", "November", "December",
+	},
+}
+
+// Describe returns the English description of the spec parsed with options,
+// e.g. "Every 15 minutes, between 09:00 and 17:59, Monday through Friday"
+// for "*/15 9-17 * * mon-fri".
+func Describe(spec string, options Option) (string, error) {
+	return English.Describe(spec, options)
+}
+
+// Describe returns the description of the spec parsed with options.
+func (p *Phrases) Describe(spec string, options Option) (string, error) {
+	schedule, err := New(options).Parse(spec)
+	if err != nil {
+		return "", err
+	}
+
+	var segments []string
+	switch s := schedule.(type) {
+	case everySchedule:
+		segments = append(segments, fmt.Sprintf(p.Every, s.interval))
+	case *specSchedule:
+		segments = append(segments, p.describeTime(s)...)
+		segments = append(segments, p.describeDays(s)...)
+		segments = append(segments, p.describeMonths(s)...)
+		segments = append(segments, p.describeYears(s)...)
+		if s.Location != time.Local {
+			segments = append(segments, fmt.Sprintf(p.InLocation, s.Location))
+		}
+	}
+	return capitalize(strings.Join(segments, p.Separator)), nil
+}
+
+// describeTime describes the second, minute and hour fields.
+func (p *Phrases) describeTime(s *specSchedule) []string {
+	second := getValues(s.Second, secondBonds)
+	minute := getValues(s.Minute, minuteBounds)
+	hour := getValues(s.Hour, hourBounds)
+
+	// The fixed times in a day, e.g. "at 09:00 and 18:00".
+	if second.kind == kindSingle && minute.kind == kindSingle && (hour.kind == kindSingle || hour.kind == kindList) {
+		times := make([]string, 0, len(hour.values))
+		for _, h := range hour.values {
+			times = append(times, formatTime(h, minute.values[0], second.values[0]))
+		}
+		return []string{fmt.Sprintf(p.At, p.list(times))}
+	}
+	// The fixed time in a range of hours, e.g. "every hour from 09:00 through 17:00".
+	if second.kind == kindSingle && minute.kind == kindSingle && hour.kind == kindRange {
+		from := formatTime(hour.values[0], minute.values[0], second.values[0])
+		to := formatTime(hour.values[len(hour.values)-1], minute.values[0], second.values[0])
+		return []string{fmt.Sprintf(p.EveryHourTo, from, to)}
+	}
+
+	var segments []string
+	switch second.kind {
+	case kindAll:
+		segments = append(segments, p.EverySecond)
+	case kindSingle:
+		if second.values[0] != 0 {
+			segments = append(segments, fmt.Sprintf(p.AtSeconds, p.numbers(second.values)))
+		}
+	case kindRange:
+		segments = append(segments, fmt.Sprintf(p.SecondsTo, second.first(), second.last()))
+	case kindStep:
+		segments = append(segments, fmt.Sprintf(p.EverySeconds, strconv.Itoa(int(second.step))))
+	case kindList:
+		segments = append(segments, fmt.Sprintf(p.AtSeconds, p.numbers(second.values)))
+	}
+
+	switch minute.kind {
+	case kindAll:
+		if len(segments) == 0 {
+			segments = append(segments, p.EveryMinute)
+		}
+	case kindSingle:
+		if minute.values[0] == 0 && hour.kind == kindAll && len(segments) == 0 {
+			segments = append(segments, p.EveryHour)
+		} else if hour.kind == kindStep {
+			segments = append(segments, fmt.Sprintf(p.AtMinute, p.numbers(minute.values)))
+		} else {
+			segments = append(segments, fmt.Sprintf(p.AtMinutes, p.numbers(minute.values)))
+		}
+	case kindRange:
+		segments = append(segments, fmt.Sprintf(p.MinutesTo, minute.first(), minute.last()))
+	case kindStep:
+		segments = append(segments, fmt.Sprintf(p.EveryMinutes, strconv.Itoa(int(minute.step))))
+	case kindList:
+		segments = append(segments, fmt.Sprintf(p.AtMinutes, p.numbers(minute.values)))
+	}
+
+	switch hour.kind {
+	case kindSingle, kindRange:
+		from := formatTime(hour.values[0], 0, 0)
+		to := formatTime(hour.values[len(hour.values)-1], 59, 0)
+		segments = append(segments, fmt.Sprintf(p.Between, from, to))
+	case kindStep:
+		segments = append(segments, fmt.Sprintf(p.EveryHours, strconv.Itoa(int(hour.step))))
+	case kindList:
+		segments = append(segments, fmt.Sprintf(p.DuringHours, p.numbers(hour.values)))
+	}
+	return segments
+}
+
+// describeDays describes the day of month and day of week fields.
+func (p *Phrases) describeDays(s *specSchedule) []string {
+	var domPhrases []string
+	dom := getValues(s.Dom, domBounds)
+	switch dom.kind {
+	case kindSingle, kindList:
+		domPhrases = append(domPhrases, fmt.Sprintf(p.OnDays, p.numbers(dom.values)))
+	case kindRange:
+		domPhrases = append(domPhrases, fmt.Sprintf(p.DaysBetween, dom.first(), dom.last()))
+	case kindStep:
+		domPhrases = append(domPhrases, fmt.Sprintf(p.EveryDays, strconv.Itoa(int(dom.step))))
+	}
+	if s.DomLast {
+		domPhrases = append(domPhrases, p.LastDay)
+	}
+	if s.DomLastWeekday {
+		domPhrases = append(domPhrases, p.LastWeekday)
+	}
+	if s.DomWeekday != 0 {
+		days := getValues(s.DomWeekday, domBounds)
+		domPhrases = append(domPhrases, fmt.Sprintf(p.NearestWeekday, p.numbers(days.values)))
+	}
+
+	var dowPhrases []string
+	dow := getValues(s.Dow, dowBounds)
+	switch dow.kind {
+	case kindSingle, kindStep, kindList:
+		dowPhrases = append(dowPhrases, fmt.Sprintf(p.OnlyOn, p.list(p.names(dow.values, p.Weekdays[:], 0))))
+	case kindRange:
+		dowPhrases = append(dowPhrases, fmt.Sprintf(p.Through, p.Weekdays[dow.values[0]], p.Weekdays[dow.values[len(dow.values)-1]]))
+	}
+	for weekday := dowBounds.min; weekday <= dowBounds.max; weekday++ {
+		if 1<<weekday&s.DowLast > 0 {
+			dowPhrases = append(dowPhrases, fmt.Sprintf(p.LastDow, p.Weekdays[weekday]))
+		}
+		for nth := uint(1); nth <= uint(len(p.Ordinals)); nth++ {
+			if 1<<(weekday*8+nth)&s.DowNth > 0 {
+				dowPhrases = append(dowPhrases, fmt.Sprintf(p.NthDow, p.Ordinals[nth-1], p.Weekdays[weekday]))
+			}
+		}
+	}
+
+	// Both of the day of month and day of week must be satisfied if one has a
+	// star, otherwise only one needs to be.
+	if s.Dom&starBit > 0 || s.Dow&starBit > 0 {
+		return append(p.alternatives(domPhrases), p.alternatives(dowPhrases)...)
+	}
+	return p.alternatives(append(domPhrases, dowPhrases...))
+}
+
+// describeMonths describes the month field.
+func (p *Phrases) describeMonths(s *specSchedule) []string {
+	month := getValues(s.Month, monthBounds)
+	switch month.kind {
+	case kindSingle, kindList:
+		return []string{fmt.Sprintf(p.OnlyIn, p.list(p.names(month.values, p.Months[:], monthBounds.min)))}
+	case kindRange:
+		return []string{fmt.Sprintf(p.Through, p.Months[month.values[0]-1], p.Months[month.values[len(month.values)-1]-1])}
+	case kindStep:
+		return []string{fmt.Sprintf(p.EveryMonths, strconv.Itoa(int(month.step)))}
+	}
+	return nil
+}
+
+// describeYears describes the year field.
+func (p *Phrases) describeYears(s *specSchedule) []string {
+	if len(s.Year) == 0 {
+		return nil
+	}
+	first, last := s.Year[0], s.Year[len(s.Year)-1]
+	if len(s.Year) > 2 && last-first == len(s.Year)-1 {
+		return []string{fmt.Sprintf(p.Through, strconv.Itoa(first), strconv.Itoa(last))}
+	}
+	years := make([]string, 0, len(s.Year))
+	for _, year := range s.Year {
+		years = append(years, strconv.Itoa(year))
+	}
+	return []string{fmt.Sprintf(p.OnlyIn, p.list(years))}
+}
+
+// alternatives returns the phrases that only one needs to be satisfied.
+func (p *Phrases) alternatives(phrases []string) []string {
+	for i := 1; i < len(phrases); i++ {
+		phrases[i] = fmt.Sprintf(p.Or, phrases[i])
+	}
+	return phrases
+}
+
+// numbers returns the list of values.
+func (p *Phrases) numbers(values []uint) string {
+	items := make([]string, 0, len(values))
+	for _, v := range values {
+		items = append(items, strconv.Itoa(int(v)))
+	}
+	return p.list(items)
+}
+
+// names returns the names of values, the names[0] is the name of value min.
+func (p *Phrases) names(values []uint, names []string, min uint) []string {
+	items := make([]string, 0, len(values))
+	for _, v := range values {
+		items = append(items, names[v-min])
+	}
+	return items
+}
+
+// list joins the items, e.g. "a, b and c".
+func (p *Phrases) list(items []string) string {
+	if len(items) <= 1 {
+		return strings.Join(items, "")
+	}
+	return strings.Join(items[:len(items)-1], p.Separator) + p.Conjunction + items[len(items)-1]
+}
+
+// The kind of the values of a field.
+const (
+	kindNone   = iota // No value, e.g. the day of month only has "L".
+	kindAll           // Every value, e.g. "*".
+	kindSingle        // A single value, e.g. "5".
+	kindRange         // A range with step 1, e.g. "1-5".
+	kindStep          // At least 3 values from minimum to end with step, e.g. "*/15".
+	kindList          // Other values, e.g. "1,3,7".
+)
+
+// fieldValues is the values of a field that set in bits.
+type fieldValues struct {
+	kind   int
+	values []uint
+	step   uint
+}
+
+func (f fieldValues) first() string {
+	return strconv.Itoa(int(f.values[0]))
+}
+
+func (f fieldValues) last() string {
+	return strconv.Itoa(int(f.values[len(f.values)-1]))
+}
+
+// getValues returns the values set in the bits within the given bounds.
+func getValues(bits uint64, r bounds) fieldValues {
+	f := fieldValues{}
+	for i := r.min; i <= r.max; i++ {
+		if 1<<i&bits > 0 {
+			f.values = append(f.values, i)
+		}
+	}
+
+	switch {
+	case bits&starBit > 0 || len(f.values) == int(r.max-r.min+1):
+		f.kind = kindAll
+	case len(f.values) == 0:
+		f.kind = kindNone
+	case len(f.values) == 1:
+		f.kind = kindSingle
+	default:
+		step := f.values[1] - f.values[0]
+		for i := 2; i < len(f.values); i++ {
+			if f.values[i]-f.values[i-1] != step {
+				f.kind = kindList
+				return f
+			}
+		}
+		switch {
+		case step == 1:
+			f.kind = kindRange
+		case len(f.values) > 2 && f.values[0] == r.min && f.values[len(f.values)-1]+step > r.max:
+			f.kind = kindStep
+			f.step = step
+		default:
+			f.kind = kindList
+		}
+	}
+	return f
+}
+
+// formatTime returns the time of day, the seconds is omitted if zero.
+func formatTime(hour, minute, second uint) string {
+	if second == 0 {
+		return fmt.Sprintf("%02d:%02d", hour, minute)
+	}
+	return fmt.Sprintf("%02d:%02d:%02d", hour, minute, second)
+}
+
+// capitalize returns s with the first letter in upper case.
+func capitalize(s string) string {
+	r, size := utf8.DecodeRuneInString(s)
+	if r == utf8.RuneError {
+		return s
+	}
+	return string(unicode.ToUpper(r)) + s[size:]
+}
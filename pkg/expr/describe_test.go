@@ -0,0 +1,86 @@
+package expr
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestExpr_Describe(t *testing.T) {
+	standard := Minute | Hour | Dom | Month | Dow | Descriptor
+	tests := []struct {
+		spec     string
+		options  Option
+		expected string
+	}{
+		{"*/15 9-17 * * mon-fri", standard, "Every 15 minutes, between 09:00 and 17:59, Monday through Friday"},
+		{"* * * * *", standard, "Every minute"},
+		{"0 * * * *", standard, "Every hour"},
+		{"30 9 * * *", standard, "At 09:30"},
+		{"0 9,18 * * *", standard, "At 09:00 and 18:00"},
+		{"5,10,20 * * * *", standard, "At 5, 10 and 20 minutes past the hour"},
+		{"10-20 */2 * * *", standard, "Minutes 10 through 20 past the hour, every 2 hours"},
+		{"0 9-17 * * *", standard, "Every hour from 09:00 through 17:00"},
+		{"30 9-17 * * mon-fri", standard, "Every hour from 09:30 through 17:30, Monday through Friday"},
+		{"0 */2 * * *", standard, "At minute 0, every 2 hours"},
+		{"15 */6 * * *", standard, "At minute 15, every 6 hours"},
+		{"5,10 9-17 * * *", standard, "At 5 and 10 minutes past the hour, between 09:00 and 17:59"},
+		{"0 0 1,15 * *", standard, "At 00:00, on day 1 and 15 of the month"},
+		{"0 0 1-7 * *", standard, "At 00:00, between day 1 and 7 of the month"},
+		{"0 0 1,15 * sun", standard, "At 00:00, on day 1 and 15 of the month, or only on Sunday"},
+		{"0 0 * jan,jul sat,sun", standard, "At 00:00, only on Sunday and Saturday, only in January and July"},
+		{"0 0 1 */3 *", standard, "At 00:00, on day 1 of the month, every 3 months"},
+		{"0 0 * 6-8 *", standard, "At 00:00, June through August"},
+		{"0 0 L * ?", standard, "At 00:00, on the last day of the month"},
+		{"0 0 LW * ?", standard, "At 00:00, on the last weekday of the month"},
+		{"0 0 15W * ?", standard, "At 00:00, on the weekday nearest day 15 of the month"},
+		{"0 0 ? * 5L", standard, "At 00:00, on the last Friday of the month"},
+		{"0 0 ? * 5#3", standard, "At 00:00, on the third Friday of the month"},
+		{"0 0 1,L * ?", standard, "At 00:00, on day 1 of the month, or on the last day of the month"},
+		{"0 0 1 1 * 2027-2030", standard | Year, "At 00:00, on day 1 of the month, only in January, 2027 through 2030"},
+		{"0 0 1 1 * 2027,2029", standard | Year, "At 00:00, on day 1 of the month, only in January, only in 2027 and 2029"},
+		{"* * * * * *", Second | standard, "Every second"},
+		{"*/10 * * * * *", Second | standard, "Every 10 seconds"},
+		{"30 0 12 * * *", Second | standard, "At 12:00:30"},
+		{"15 * 9 * * *", Second | standard, "At 15 seconds past the minute, between 09:00 and 09:59"},
+		{"15 0 9-17 * * *", Second | standard, "Every hour from 09:00:15 through 17:00:15"},
+		{"TZ=UTC 0 0 * * *", standard, "At 00:00, in UTC time"},
+		{"@daily", standard, "At 00:00"},
+		{"@hourly", standard, "Every hour"},
+		{"@weekly", standard, "At 00:00, only on Sunday"},
+		{"@monthly", standard, "At 00:00, on day 1 of the month"},
+		{"@yearly", standard, "At 00:00, on day 1 of the month, only in January"},
+		{"@every 1h30m", standard, "Every 1h30m0s"},
+	}
+	for _, c := range tests {
+		actual, err := Describe(c.spec, c.options)
+		if err != nil {
+			t.Errorf("%s => unexpected error %v", c.spec, err)
+		}
+		if actual != c.expected {
+			t.Errorf("%s => expected %q, got %q", c.spec, c.expected, actual)
+		}
+	}
+}
+
+func TestExpr_DescribeErrors(t *testing.T) {
+	_, err := Describe("* * *", Minute|Hour|Dom|Month|Dow)
+	if err == nil || !strings.Contains(err.Error(), "expected exactly 5 fields") {
+		t.Errorf("expected error, got %v", err)
+	}
+}
+
+func TestExpr_DescribePhrases(t *testing.T) {
+	phrases := *English
+	phrases.At = "um %s"
+	phrases.OnlyOn = "nur am %s"
+	phrases.Conjunction = " und "
+	phrases.Weekdays = [7]string{"Sonntag", "Montag", "Dienstag", "Mittwoch", "Donnerstag", "Freitag", "Samstag"}
+
+	actual, err := phrases.Describe("30 9 * * sat,sun", Minute|Hour|Dom|Month|Dow)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if expected := "Um 09:30, nur am Sonntag und Samstag"; actual != expected {
+		t.Errorf("expected %q, got %q", expected, actual)
+	}
+}
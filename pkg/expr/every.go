@@ -14,6 +14,16 @@ func (schedule everySchedule) Next(t time.Time) time.Time {
 	return t.Add(schedule.interval)
 }
 
+// Prev returns the previous time this should be run.
+func (schedule everySchedule) Prev(t time.Time) time.Time {
+	return t.Add(-schedule.interval)
+}
+
+// String returns the descriptor of the schedule, e.g. "@every 1h30m0s".
+func (schedule everySchedule) String() string {
+	return "@every " + schedule.interval.String()
+}
+
 // Every returns a crontab Schedule that activates once every duration.
 func Every(interval time.Duration) Schedule {
 	return everySchedule{interval: interval}
@@ -1,10 +1,17 @@
 package expr
 
-import "time"
+import (
+	"fmt"
+	"reflect"
+	"time"
+)
 
 var (
-	_ Schedule = (*specSchedule)(nil)
-	_ Schedule = (*everySchedule)(nil)
+	_ ReverseSchedule = (*specSchedule)(nil)
+	_ ReverseSchedule = (*everySchedule)(nil)
+
+	_ fmt.Stringer = (*specSchedule)(nil)
+	_ fmt.Stringer = (*everySchedule)(nil)
 )
 
 // Schedule describes a job's duty cycle.
@@ -14,6 +21,15 @@ type Schedule interface {
 	Next(time.Time) time.Time
 }
 
+// ReverseSchedule is a Schedule that can find the previous activation time.
+// The Schedule returned by Parser implements it.
+type ReverseSchedule interface {
+	Schedule
+
+	// Prev returns the previous activation time, earlier than the given time.
+	Prev(time.Time) time.Time
+}
+
 // Standard represents a Parser to parse standard crontab expression.
 // Sed spec (https://en.wikipedia.org/wiki/Cron). It requires 5 entries
 // representing: minute, hour, day of month, month and day of week, in that
@@ -23,3 +39,34 @@ type Schedule interface {
 //   - Standard crontab specs, e.g. "* * * * ?"
 //   - Descriptors, e.g. "@midnight", "@every 1h30m"
 var Standard = New(Minute | Hour | Dom | Month | Dow | Descriptor)
+
+// WithSeconds represents a Parser to parse crontab expression with seconds.
+// It requires 6 entries representing: second, minute, hour, day of month,
+// month and day of week, in that order.
+//
+// It accepts
+//   - Crontab specs with seconds, e.g. "0 */5 * * * ?"
+//   - Descriptors, e.g. "@midnight", "@every 1h30m"
+var WithSeconds = New(Second | Minute | Hour | Dom | Month | Dow | Descriptor)
+
+// Equal reports whether the schedules are equal. The Schedule returned by
+// Parser are compared by their canonical expression, e.g. "0-59/15 * * * *"
+// equals to "*/15 * * * *". Others are compared by reflect.DeepEqual.
+func Equal(a, b Schedule) bool {
+	if sa, ok := canonical(a); ok {
+		sb, ok := canonical(b)
+		return ok && sa == sb
+	}
+	return reflect.DeepEqual(a, b)
+}
+
+// canonical returns the canonical expression of the Schedule returned by Parser.
+func canonical(s Schedule) (string, bool) {
+	switch v := s.(type) {
+	case *specSchedule:
+		return v.format(allFields), true
+	case everySchedule:
+		return v.String(), true
+	}
+	return "", false
+}
@@ -0,0 +1,35 @@
+package expr
+
+import "time"
+
+// NextN returns at most n activation times of the schedule later than from,
+// in ascending order. It works with any Schedule, the iteration stops if the
+// schedule is exhausted or does not move forward.
+func NextN(s Schedule, from time.Time, n int) []time.Time {
+	var times []time.Time
+	for t := from; len(times) < n; {
+		next := s.Next(t)
+		if next.IsZero() || !next.After(t) {
+			break
+		}
+		times = append(times, next)
+		t = next
+	}
+	return times
+}
+
+// Between returns the activation times of the schedule later than from and not
+// later than to, in ascending order. It works with any Schedule, the iteration
+// stops if the schedule is exhausted or does not move forward.
+func Between(s Schedule, from time.Time, to time.Time) []time.Time {
+	var times []time.Time
+	for t := from; ; {
+		next := s.Next(t)
+		if next.IsZero() || !next.After(t) || next.After(to) {
+			break
+		}
+		times = append(times, next)
+		t = next
+	}
+	return times
+}
@@ -0,0 +1,60 @@
+package expr
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestExpr_NextN(t *testing.T) {
+	sched, err := Standard.Parse("0 9,18 * * *")
+	if err != nil {
+		t.Fatal(err)
+	}
+	from := getTime("Mon Jul 9 12:00 2012")
+	expected := []time.Time{
+		getTime("Mon Jul 9 18:00 2012"),
+		getTime("Tue Jul 10 09:00 2012"),
+		getTime("Tue Jul 10 18:00 2012"),
+	}
+	if actual := NextN(sched, from, 3); !reflect.DeepEqual(actual, expected) {
+		t.Errorf("expected %v, got %v", expected, actual)
+	}
+	if actual := NextN(sched, from, 0); len(actual) != 0 {
+		t.Errorf("expected empty, got %v", actual)
+	}
+
+	// Stop if the schedule is exhausted.
+	sched, err = New(Minute | Hour | Dom | Month | Dow | Year).Parse("0 0 1 1 * 2013-2014")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if actual := NextN(sched, from, 10); len(actual) != 2 {
+		t.Errorf("expected 2 times, got %v", actual)
+	}
+}
+
+func TestExpr_Between(t *testing.T) {
+	sched, err := Standard.Parse("0 9,18 * * *")
+	if err != nil {
+		t.Fatal(err)
+	}
+	from := getTime("Mon Jul 9 09:00 2012")
+	to := getTime("Tue Jul 10 18:00 2012")
+	expected := []time.Time{
+		getTime("Mon Jul 9 18:00 2012"),
+		getTime("Tue Jul 10 09:00 2012"),
+		getTime("Tue Jul 10 18:00 2012"),
+	}
+	if actual := Between(sched, from, to); !reflect.DeepEqual(actual, expected) {
+		t.Errorf("expected %v, got %v", expected, actual)
+	}
+	if actual := Between(sched, to, from); len(actual) != 0 {
+		t.Errorf("expected empty, got %v", actual)
+	}
+
+	// Any Schedule that implements Next.
+	if actual := Between(Every(time.Hour), from, from.Add(time.Hour*3)); len(actual) != 3 {
+		t.Errorf("expected 3 times, got %v", actual)
+	}
+}
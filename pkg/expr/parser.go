@@ -2,6 +2,7 @@ package expr
 
 import (
 	"fmt"
+	"hash/fnv"
 	"math"
 	"strconv"
 	"strings"
@@ -15,13 +16,15 @@ import (
 type Option int
 
 const (
-	Second     Option = 1 << iota // Seconds field, default 0
-	Minute                        // Minutes field, default 0
-	Hour                          // Hours field, default 0
-	Dom                           // Day of month field, default *
-	Month                         // Month field, default *
-	Dow                           // Day of week field, default *
-	Descriptor                    // Allow descriptors such as @monthly, @weekly, etc.
+	Second       Option = 1 << iota // Seconds field, default 0
+	Minute                          // Minutes field, default 0
+	Hour                            // Hours field, default 0
+	Dom                             // Day of month field, default *
+	Month                           // Month field, default *
+	Dow                             // Day of week field, default *
+	Descriptor                      // Allow descriptors such as @monthly, @weekly, etc.
+	Year                            // Year field, default *
+	YearOptional                    // Optional year field, default *
 )
 
 var places = []Option{
@@ -31,6 +34,7 @@ var places = []Option{
 	Dom,
 	Month,
 	Dow,
+	Year,
 }
 
 var defaults = []string{
@@ -40,6 +44,7 @@ var defaults = []string{
 	"*",
 	"*",
 	"*",
+	"*",
 }
 
 // Parser A custom parser that can be configured.
@@ -54,22 +59,41 @@ type Parser struct {
 //
 // Examples
 //
-//  // Standard expr without descriptors
-//  specParser := New(Minute | Hour | Dom | Month | Dow)
-//  sched, err := expr.Parse("0 0 15 */3 *")
-//
-//  // Same as above, just excludes time fields
-//  subsParser := New(Dom | Month | Dow)
-//  sched, err := expr.Parse("15 */3 *")
+//	// Standard expr without descriptors
+//	specParser := New(Minute | Hour | Dom | Month | Dow)
+//	sched, err := expr.Parse("0 0 15 */3 *")
 //
+//	// Same as above, just excludes time fields
+//	subsParser := New(Dom | Month | Dow)
+//	sched, err := expr.Parse("15 */3 *")
 func New(options Option) Parser {
 	return Parser{options: options}
 }
 
+// Options returns the options that the Parser created with.
+func (p Parser) Options() Option {
+	return p.options
+}
+
 // Parse returns a new crontab schedule representing the given spec.
 // It returns a descriptive error if the spec is not valid.
 // It accepts crontab specs and features configured by New.
+//
+// The hashed "H" tokens are resolved with the empty key, see ParseWithKey.
 func (p Parser) Parse(spec string) (Schedule, error) {
+	return p.ParseWithKey(spec, "")
+}
+
+// ParseWithKey is the same as Parse, but resolves the hashed "H" tokens by the
+// key, so that the schedules with different keys spread over the range while
+// each key always lands on the same value. The tokens are:
+//
+//	"H" | "H(" number "-" number ")" [ "/" number ]
+//
+// e.g. "H * * * *" runs once an hour at a stable minute, "H(0-29)/10 * * * *"
+// runs every 10 minutes from a stable minute in 0-9. The "H" in the day of
+// month field is in range 1-28, to be valid in every month.
+func (p Parser) ParseWithKey(spec string, key string) (Schedule, error) {
 	if len(spec) == 0 {
 		return nil, fmt.Errorf("expr: empty spec string")
 	}
@@ -91,7 +115,11 @@ func (p Parser) Parse(spec string) (Schedule, error) {
 		if p.options&Descriptor == 0 {
 			return nil, fmt.Errorf("expr: does not accept descriptors: %v", spec)
 		}
-		return parseDescriptor(spec, loc)
+		schedule, err := parseDescriptor(spec, loc)
+		if s, ok := schedule.(*specSchedule); ok {
+			s.options = p.options
+		}
+		return schedule, err
 	}
 
 	// Split on whitespace.
@@ -103,6 +131,14 @@ func (p Parser) Parse(spec string) (Schedule, error) {
 		return nil, err
 	}
 
+	// Resolve the hashed tokens except the year, each field with its own hash
+	// so that the fields of different keys are not correlated.
+	for i, r := range hashFields {
+		if fields[i], err = resolveHash(fields[i], r, hashKey(key, i)); err != nil {
+			return nil, err
+		}
+	}
+
 	field := func(field string, r bounds) uint64 {
 		if err != nil {
 			return 0
@@ -116,23 +152,117 @@ func (p Parser) Parse(spec string) (Schedule, error) {
 		second = field(fields[0], secondBonds)
 		minute = field(fields[1], minuteBounds)
 		hour   = field(fields[2], hourBounds)
-		dom    = field(fields[3], domBounds)
 		month  = field(fields[4], monthBounds)
-		dow    = field(fields[5], dowBounds)
 	)
 	if err != nil {
 		return nil, err
 	}
+	year, err := getYears(fields[6])
+	if err != nil {
+		return nil, err
+	}
 
-	return &specSchedule{
+	schedule := &specSchedule{
 		Second:   second,
 		Minute:   minute,
 		Hour:     hour,
-		Dom:      dom,
 		Month:    month,
-		Dow:      dow,
+		Year:     year,
 		Location: loc,
-	}, nil
+		options:  p.options,
+	}
+	if err = schedule.parseDom(fields[3]); err != nil {
+		return nil, err
+	}
+	if err = schedule.parseDow(fields[5]); err != nil {
+		return nil, err
+	}
+	return schedule, nil
+}
+
+// parseDom parses the day of month field, which accepts the Quartz-style
+// "L" (last day of month), "LW" (last weekday of month) and "15W" (the
+// nearest weekday to the 15th) in addition to the ranges.
+func (s *specSchedule) parseDom(field string) error {
+	for _, expr := range strings.Split(field, ",") {
+		upper := strings.ToUpper(expr)
+		switch {
+		case upper == "L":
+			s.DomLast = true
+		case upper == "LW":
+			s.DomLastWeekday = true
+		case len(upper) > 1 && strings.HasSuffix(upper, "W"):
+			day, err := mustParseInt(expr[:len(expr)-1])
+			if err != nil {
+				return err
+			}
+			if day < domBounds.min || day > domBounds.max {
+				return fmt.Errorf("expr: day of month (%d) out of range [%d, %d]: %s", day, domBounds.min, domBounds.max, expr)
+			}
+			s.DomWeekday |= 1 << day
+		default:
+			bits, err := getRange(expr, domBounds)
+			if err != nil {
+				return err
+			}
+			s.Dom |= bits
+		}
+	}
+	return nil
+}
+
+// parseDow parses the day of week field, which accepts the Quartz-style
+// "5L" (last Friday of month), "L" (last Saturday of month) and "5#3" (the
+// third Friday of month) in addition to the ranges.
+func (s *specSchedule) parseDow(field string) error {
+	for _, expr := range strings.Split(field, ",") {
+		switch {
+		case strings.EqualFold(expr, "L"):
+			s.DowLast |= 1 << dowBounds.max
+		case len(expr) > 1 && (expr[len(expr)-1] == 'L' || expr[len(expr)-1] == 'l'):
+			weekday, err := parseWeekday(expr[:len(expr)-1])
+			if err != nil {
+				return err
+			}
+			s.DowLast |= 1 << weekday
+		case strings.Contains(expr, "#"):
+			parts := strings.Split(expr, "#")
+			if len(parts) != 2 {
+				return fmt.Errorf("expr: too many hashes: %s", expr)
+			}
+			weekday, err := parseWeekday(parts[0])
+			if err != nil {
+				return err
+			}
+			nth, err := mustParseInt(parts[1])
+			if err != nil {
+				return err
+			}
+			if nth < 1 || nth > 5 {
+				return fmt.Errorf("expr: nth day of week (%d) out of range [1, 5]: %s", nth, expr)
+			}
+			s.DowNth |= 1 << (weekday*8 + nth)
+		default:
+			bits, err := getRange(expr, dowBounds)
+			if err != nil {
+				return err
+			}
+			s.Dow |= bits
+		}
+	}
+	return nil
+}
+
+// parseWeekday returns the (possibly-named) day of week contained in expr.
+func parseWeekday(expr string) (uint, error) {
+	weekday, err := parseIntOrName(expr, dowBounds.names)
+	if err != nil {
+		return 0, err
+	}
+	if weekday > dowBounds.max {
+		return 0, fmt.Errorf("expr: day of week (%d) above maximum (%d): %s", weekday, dowBounds.max, expr)
+	}
+	return weekday, nil
 }
 
 // normalizeFields takes a subset set of the time fields and returns the full set
@@ -141,16 +271,33 @@ func (p Parser) Parse(spec string) (Schedule, error) {
 // As part of performing this function, it also validates that the provided
 // fields are compatible with the configured options.
 func normalizeFields(fields []string, options Option) ([]string, error) {
+	// The optional year is the last field, it can be omitted.
+	optionals := 0
+	if options&YearOptional > 0 {
+		options |= Year
+		optionals++
+	}
+
 	// Figure out how many fields we need
-	num := 0
+	max := 0
 	for _, place := range places {
 		if options&place > 0 {
-			num++
+			max++
 		}
 	}
+	min := max - optionals
+
 	// Validate number of fields
-	if len(fields) != num {
-		return nil, fmt.Errorf("expr: expected exactly %d fields, found %d: %s", num, len(fields), fields)
+	if count := len(fields); count < min || count > max {
+		if min == max {
+			return nil, fmt.Errorf("expr: expected exactly %d fields, found %d: %s", min, count, fields)
+		}
+		return nil, fmt.Errorf("expr: expected %d to %d fields, found %d: %s", min, max, count, fields)
+	}
+
+	// Populate the default of optional year if it's omitted.
+	if len(fields) < max {
+		fields = append(fields, defaults[len(defaults)-1])
 	}
 
 	// Populate all fields not part of options with their defaults
@@ -166,6 +313,83 @@ func normalizeFields(fields []string, options Option) ([]string, error) {
 	return expandedFields, nil
 }
 
+// hashKey returns the hash of key used to resolve the hashed tokens of the
+// field at the index.
+func hashKey(key string, index int) uint {
+	h := fnv.New32a()
+	_, _ = h.Write([]byte(key))
+	// Derives the hash of each field and mixes its bits with the finalizer of
+	// MurmurHash3, so that the values of fields are not correlated.
+	x := h.Sum32() + uint32(index)*0x9e3779b9
+	x ^= x >> 16
+	x *= 0x85ebca6b
+	x ^= x >> 13
+	x *= 0xc2b2ae35
+	x ^= x >> 16
+	return uint(x)
+}
+
+// resolveHash replaces the hashed tokens in field with the values derived from
+// hash within the given bounds.
+func resolveHash(field string, r hashBounds, hash uint) (string, error) {
+	if !strings.Contains(field, "H") {
+		return field, nil
+	}
+	items := strings.Split(field, ",")
+	for i, item := range items {
+		if !strings.HasPrefix(item, "H") {
+			continue
+		}
+		low, high := r.low, r.high
+		rangeAndStep := strings.Split(item[1:], "/")
+		if rng := rangeAndStep[0]; rng != "" {
+			if !strings.HasPrefix(rng, "(") || !strings.HasSuffix(rng, ")") {
+				return "", fmt.Errorf("expr: invalid hash range: %s", item)
+			}
+			lowAndHigh := strings.Split(rng[1:len(rng)-1], "-")
+			if len(lowAndHigh) != 2 {
+				return "", fmt.Errorf("expr: invalid hash range: %s", item)
+			}
+			var err error
+			if low, err = mustParseInt(lowAndHigh[0]); err != nil {
+				return "", err
+			}
+			if high, err = mustParseInt(lowAndHigh[1]); err != nil {
+				return "", err
+			}
+			if low < r.min {
+				return "", fmt.Errorf("expr: beginning of range (%d) below minimum (%d): %s", low, r.min, item)
+			}
+			if high > r.max {
+				return "", fmt.Errorf("expr: end of range (%d) above maximum (%d): %s", high, r.max, item)
+			}
+			if low > high {
+				return "", fmt.Errorf("expr: beginning of range (%d) beyond end of range (%d): %s", low, high, item)
+			}
+		}
+
+		switch len(rangeAndStep) {
+		case 1:
+			items[i] = strconv.Itoa(int(low + hash%(high-low+1)))
+		case 2:
+			step, err := mustParseInt(rangeAndStep[1])
+			if err != nil {
+				return "", err
+			}
+			if step == 0 {
+				return "", fmt.Errorf("expr: step of range should be a positive number: %s", item)
+			}
+			if step > high-low+1 {
+				step = high - low + 1
+			}
+			items[i] = fmt.Sprintf("%d-%d/%s", low+hash%step, high, rangeAndStep[1])
+		default:
+			return "", fmt.Errorf("expr: too many slashes: %s", item)
+		}
+	}
+	return strings.Join(items, ","), nil
+}
+
 // getField returns an Int with the bits set representing all of the times that
 // the field represents or error parsing field value.  A "field" is a comma-separated
 // list of "ranges".
@@ -182,27 +406,66 @@ func getField(field string, r bounds) (uint64, error) {
 	return bits, nil
 }
 
+// getYears returns the years indicated by the given field in ascending order.
+// It returns nil if the field matches every year.
+func getYears(field string) ([]int, error) {
+	var set [yearMax - yearMin + 1]bool
+	for _, expr := range strings.FieldsFunc(field, func(r rune) bool { return r == ',' }) {
+		start, end, step, star, err := parseRange(expr, yearBounds)
+		if err != nil {
+			return nil, err
+		}
+		if star {
+			return nil, nil
+		}
+		for i := start; i <= end; i += step {
+			set[i-yearMin] = true
+		}
+	}
+
+	var years []int
+	for i, ok := range set {
+		if ok {
+			years = append(years, yearMin+i)
+		}
+	}
+	return years, nil
+}
+
 // getRange returns the bits indicated by the given expression:
-//   number | number "-" number [ "/" number ]
+//
+//	number | number "-" number [ "/" number ]
+//
 // or error parsing range.
 func getRange(expr string, r bounds) (uint64, error) {
+	start, end, step, star, err := parseRange(expr, r)
+	if err != nil {
+		return 0, err
+	}
+	var extra uint64
+	if star {
+		extra = starBit
+	}
+	return getBits(start, end, step) | extra, nil
+}
+
+// parseRange returns the start, end and step indicated by the given expression.
+// The star reports whether the expression matches every value without step.
+func parseRange(expr string, r bounds) (start, end, step uint, star bool, err error) {
 	var (
-		start, end, step uint
-		rangeAndStep     = strings.Split(expr, "/")
-		lowAndHigh       = strings.Split(rangeAndStep[0], "-")
-		singleDigit      = len(lowAndHigh) == 1
-		err              error
+		rangeAndStep = strings.Split(expr, "/")
+		lowAndHigh   = strings.Split(rangeAndStep[0], "-")
+		singleDigit  = len(lowAndHigh) == 1
 	)
 
-	var extra uint64
 	if lowAndHigh[0] == "*" || lowAndHigh[0] == "?" {
 		start = r.min
 		end = r.max
-		extra = starBit
+		star = true
 	} else {
 		start, err = parseIntOrName(lowAndHigh[0], r.names)
 		if err != nil {
-			return 0, err
+			return 0, 0, 0, false, err
 		}
 		switch len(lowAndHigh) {
 		case 1:
@@ -210,10 +473,10 @@ func getRange(expr string, r bounds) (uint64, error) {
 		case 2:
 			end, err = parseIntOrName(lowAndHigh[1], r.names)
 			if err != nil {
-				return 0, err
+				return 0, 0, 0, false, err
 			}
 		default:
-			return 0, fmt.Errorf("expr: too many hyphens: %s", expr)
+			return 0, 0, 0, false, fmt.Errorf("expr: too many hyphens: %s", expr)
 		}
 	}
 
@@ -223,7 +486,7 @@ func getRange(expr string, r bounds) (uint64, error) {
 	case 2:
 		step, err = mustParseInt(rangeAndStep[1])
 		if err != nil {
-			return 0, err
+			return 0, 0, 0, false, err
 		}
 
 		// Special handling: "N/step" means "N-max/step".
@@ -231,26 +494,26 @@ func getRange(expr string, r bounds) (uint64, error) {
 			end = r.max
 		}
 		if step > 1 {
-			extra = 0
+			star = false
 		}
 	default:
-		return 0, fmt.Errorf("expr: too many slashes: %s", expr)
+		return 0, 0, 0, false, fmt.Errorf("expr: too many slashes: %s", expr)
 	}
 
 	if start < r.min {
-		return 0, fmt.Errorf("expr: beginning of range (%d) below minimum (%d): %s", start, r.min, expr)
+		return 0, 0, 0, false, fmt.Errorf("expr: beginning of range (%d) below minimum (%d): %s", start, r.min, expr)
 	}
 	if end > r.max {
-		return 0, fmt.Errorf("expr: end of range (%d) above maximum (%d): %s", end, r.max, expr)
+		return 0, 0, 0, false, fmt.Errorf("expr: end of range (%d) above maximum (%d): %s", end, r.max, expr)
 	}
 	if start > end {
-		return 0, fmt.Errorf("expr: beginning of range (%d) beyond end of range (%d): %s", start, end, expr)
+		return 0, 0, 0, false, fmt.Errorf("expr: beginning of range (%d) beyond end of range (%d): %s", start, end, expr)
 	}
 	if step == 0 {
-		return 0, fmt.Errorf("expr: step of range should be a positive number: %s", expr)
+		return 0, 0, 0, false, fmt.Errorf("expr: step of range should be a positive number: %s", expr)
 	}
 
-	return getBits(start, end, step) | extra, nil
+	return start, end, step, star, nil
 }
 
 // parseIntOrName returns the (possibly-named) integer contained in expr.
@@ -1,6 +1,7 @@
 package expr
 
 import (
+	"fmt"
 	"reflect"
 	"strings"
 	"testing"
@@ -124,6 +125,7 @@ func TestExpr_ParseScheduleErrors(t *testing.T) {
 		{"@every Xm", "failed to parse duration"},
 		{"@unrecognized", "unrecognized descriptor"},
 		{"", "empty spec string"},
+		{"0 0 0 1 1 * 2027", "expected exactly 6 fields"},
 	}
 	for _, c := range tests {
 		actual, err := secondParser.Parse(c.expr)
@@ -154,6 +156,21 @@ func TestExpr_ParseSchedule(t *testing.T) {
 		{secondParser, "TZ=Asia/Tokyo @midnight", midnight(tokyo)},
 		{secondParser, "@yearly", annual(time.Local)},
 		{secondParser, "@annually", annual(time.Local)},
+		{
+			parser: New(Minute | Hour | Dom | Month | Dow | Year),
+			expr:   "0 0 1 1 * 2027-2030,2035/5",
+			expected: &specSchedule{
+				Second:   1 << secondBonds.min,
+				Minute:   1 << 0,
+				Hour:     1 << 0,
+				Dom:      1 << 1,
+				Month:    1 << 1,
+				Dow:      allBits(dowBounds),
+				Year:     []int{2027, 2028, 2029, 2030, 2035, 2040, 2045, 2050, 2055, 2060, 2065, 2070, 2075, 2080, 2085, 2090, 2095},
+				Location: time.Local,
+			},
+		},
+		{New(Minute | Hour | Dom | Month | Dow | YearOptional), "5 * * * * *", every5min(time.Local)},
 		{
 			parser: secondParser,
 			expr:   "* 5 * * * *",
@@ -174,7 +191,7 @@ func TestExpr_ParseSchedule(t *testing.T) {
 		if err != nil {
 			t.Errorf("%s => unexpected error %v", c.expr, err)
 		}
-		if !reflect.DeepEqual(actual, c.expected) {
+		if !reflect.DeepEqual(actual, parsedBy(c.expected, c.parser)) {
 			t.Errorf("%s => expected %b, got %b", c.expr, c.expected, actual)
 		}
 	}
@@ -191,13 +208,25 @@ func TestExpr_NormalizeFields(t *testing.T) {
 			"AllFields_NoOptional",
 			[]string{"0", "5", "*", "*", "*", "*"},
 			Second | Minute | Hour | Dom | Month | Dow | Descriptor,
-			[]string{"0", "5", "*", "*", "*", "*"},
+			[]string{"0", "5", "*", "*", "*", "*", "*"},
 		},
 		{
 			"SubsetFields_NoOptional",
 			[]string{"5", "15", "*"},
 			Hour | Dom | Month,
-			[]string{"0", "0", "5", "15", "*", "*"},
+			[]string{"0", "0", "5", "15", "*", "*", "*"},
+		},
+		{
+			"AllFields_YearOptional",
+			[]string{"0", "5", "*", "*", "*", "*", "2027"},
+			Second | Minute | Hour | Dom | Month | Dow | YearOptional,
+			[]string{"0", "5", "*", "*", "*", "*", "2027"},
+		},
+		{
+			"AllFields_YearOmitted",
+			[]string{"0", "5", "*", "*", "*", "*"},
+			Second | Minute | Hour | Dom | Month | Dow | YearOptional,
+			[]string{"0", "5", "*", "*", "*", "*", "*"},
 		},
 	}
 
@@ -239,6 +268,12 @@ func TestExpr_NormalizeFields_Errors(t *testing.T) {
 			Second | Minute | Hour,
 			"",
 		},
+		{
+			"TooFewFields_YearOptional",
+			[]string{"5", "*"},
+			Minute | Hour | Dom | YearOptional,
+			"expected 3 to 4 fields",
+		},
 	}
 	for _, test := range tests {
 		t.Run(test.name, func(t *testing.T) {
@@ -261,8 +296,16 @@ func TestExpr_StandardSpecSchedule(t *testing.T) {
 		err      string
 	}{
 		{
-			expr:     "5 * * * *",
-			expected: &specSchedule{1 << secondBonds.min, 1 << 5, allBits(hourBounds), allBits(domBounds), allBits(monthBounds), allBits(dowBounds), time.Local},
+			expr: "5 * * * *",
+			expected: &specSchedule{
+				Second:   1 << secondBonds.min,
+				Minute:   1 << 5,
+				Hour:     allBits(hourBounds),
+				Dom:      allBits(domBounds),
+				Month:    allBits(monthBounds),
+				Dow:      allBits(dowBounds),
+				Location: time.Local,
+			},
 		},
 		{
 			expr:     "@every 5m",
@@ -286,7 +329,7 @@ func TestExpr_StandardSpecSchedule(t *testing.T) {
 		if len(c.err) == 0 && err != nil {
 			t.Errorf("%s => unexpected error %v", c.expr, err)
 		}
-		if !reflect.DeepEqual(actual, c.expected) {
+		if !reflect.DeepEqual(actual, parsedBy(c.expected, Standard)) {
 			t.Errorf("%s => expected %b, got %b", c.expr, c.expected, actual)
 		}
 	}
@@ -300,8 +343,24 @@ func TestExpr_NoDescriptorParser(t *testing.T) {
 	}
 }
 
+// parsedBy sets the options of the expected spec schedule to the parser's.
+func parsedBy(expected Schedule, parser Parser) Schedule {
+	if s, ok := expected.(*specSchedule); ok {
+		s.options = parser.options
+	}
+	return expected
+}
+
 func every5min(loc *time.Location) *specSchedule {
-	return &specSchedule{1 << 0, 1 << 5, allBits(hourBounds), allBits(domBounds), allBits(monthBounds), allBits(dowBounds), loc}
+	return &specSchedule{
+		Second:   1 << 0,
+		Minute:   1 << 5,
+		Hour:     allBits(hourBounds),
+		Dom:      allBits(domBounds),
+		Month:    allBits(monthBounds),
+		Dow:      allBits(dowBounds),
+		Location: loc,
+	}
 }
 
 //func every5min5s(loc *time.Location) *specSchedule {
@@ -309,7 +368,15 @@ func every5min(loc *time.Location) *specSchedule {
 //}
 
 func midnight(loc *time.Location) *specSchedule {
-	return &specSchedule{1, 1, 1, allBits(domBounds), allBits(monthBounds), allBits(dowBounds), loc}
+	return &specSchedule{
+		Second:   1,
+		Minute:   1,
+		Hour:     1,
+		Dom:      allBits(domBounds),
+		Month:    allBits(monthBounds),
+		Dow:      allBits(dowBounds),
+		Location: loc,
+	}
 }
 
 func annual(loc *time.Location) *specSchedule {
@@ -323,3 +390,88 @@ func annual(loc *time.Location) *specSchedule {
 		Location: loc,
 	}
 }
+
+func TestExpr_ParseWithKey(t *testing.T) {
+	minute, hour, dom, dow := hashKey("job1", 1), hashKey("job1", 2), hashKey("job1", 3), hashKey("job1", 5)
+	tests := []struct {
+		expr     string
+		expected string
+	}{
+		{"H * * * *", fmt.Sprintf("0 %d * * * *", minute%60)},
+		{"H H * * *", fmt.Sprintf("0 %d %d * * *", minute%60, hour%24)},
+		{"H(10-19) * * * *", fmt.Sprintf("0 %d * * * *", 10+minute%10)},
+		{"H/15 * * * *", fmt.Sprintf("0 %d/15 * * * *", minute%15)},
+		{"H(0-29)/10 * * * *", fmt.Sprintf("0 %d-%d/10 * * * *", minute%10, minute%10+20)},
+		{"0 0 H * *", fmt.Sprintf("0 0 0 %d * *", 1+dom%28)},
+		{"0 0 H(1-31) * *", fmt.Sprintf("0 0 0 %d * *", 1+dom%31)},
+		{"0 0 * * H", fmt.Sprintf("0 0 0 * * %d", dow%7)},
+		{"0,H * * * *", fmt.Sprintf("0 0,%d * * * *", minute%60)},
+	}
+	for _, c := range tests {
+		actual, err := Standard.ParseWithKey(c.expr, "job1")
+		if err != nil {
+			t.Errorf("%s => unexpected error %v", c.expr, err)
+			continue
+		}
+		expected, err := New(Second | Minute | Hour | Dom | Month | Dow).Parse(c.expected)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !Equal(actual, expected) {
+			t.Errorf("%s => expected %v, got %v", c.expr, expected, actual)
+		}
+	}
+
+	// Each key always lands on the same value.
+	a, _ := Standard.ParseWithKey("H * * * *", "job1")
+	b, _ := Standard.ParseWithKey("H * * * *", "job1")
+	if !Equal(a, b) {
+		t.Errorf("expected equal, got %v and %v", a, b)
+	}
+
+	// The keys spread over the range.
+	minutes := make(map[string]bool)
+	for i := 0; i < 100; i++ {
+		s, err := Standard.ParseWithKey("H * * * *", fmt.Sprintf("job%d", i))
+		if err != nil {
+			t.Fatal(err)
+		}
+		minutes[s.(fmt.Stringer).String()] = true
+	}
+	if len(minutes) < 30 {
+		t.Errorf("expected spread minutes, got %d", len(minutes))
+	}
+
+	// The fields are hashed independently, so the keys spread over the slots of
+	// minute and hour.
+	slots := make(map[string]bool)
+	for i := 0; i < 5000; i++ {
+		s, err := Standard.ParseWithKey("H H * * *", fmt.Sprintf("job%d", i))
+		if err != nil {
+			t.Fatal(err)
+		}
+		slots[s.(fmt.Stringer).String()] = true
+	}
+	if len(slots) < 1200 {
+		t.Errorf("expected spread slots of minute and hour, got %d", len(slots))
+	}
+}
+
+func TestExpr_ParseWithKeyErrors(t *testing.T) {
+	tests := []struct{ expr, err string }{
+		{"H(10) * * * *", "invalid hash range"},
+		{"H10-20) * * * *", "invalid hash range"},
+		{"H(20-10) * * * *", "beyond end of range"},
+		{"H(x-10) * * * *", "failed to parse int from"},
+		{"H/0 * * * *", "should be a positive number"},
+		{"H/2/2 * * * *", "too many slashes"},
+		{"H(0-60) * * * *", "above maximum"},
+		{"0 0 H(0-10) * *", "below minimum"},
+	}
+	for _, c := range tests {
+		_, err := Standard.ParseWithKey(c.expr, "job1")
+		if err == nil || !strings.Contains(err.Error(), c.err) {
+			t.Errorf("%s => expected %v, got %v", c.expr, c.err, err)
+		}
+	}
+}
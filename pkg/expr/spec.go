@@ -1,6 +1,11 @@
 package expr
 
-import "time"
+import (
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
 
 const (
 	// Set the top bit if a star was included in the expression.
@@ -12,8 +17,24 @@ const (
 type specSchedule struct {
 	Second, Minute, Hour, Dom, Month, Dow uint64
 
+	// Year is the years in ascending order. Nil means every year.
+	Year []int
+
 	// Override location for this schedule.
 	Location *time.Location
+
+	// The Quartz-style day of month that cannot be expressed by bit sets.
+	DomLast        bool   // "L", the last day of month.
+	DomLastWeekday bool   // "LW", the last weekday (Monday to Friday) of month.
+	DomWeekday     uint64 // "15W", bit n is set for the nearest weekday to day n.
+
+	// The Quartz-style day of week that cannot be expressed by bit sets.
+	DowLast uint64 // "5L", bit n is set for the last weekday n of month.
+	DowNth  uint64 // "5#3", bit (weekday*8 + nth) is set for the nth weekday of month.
+
+	// The options of the Parser that parsed the schedule, String formats only
+	// the fields of them. Zero means all fields.
+	options Option
 }
 
 // Next returns the next time this schedule is activated, greater than the given
@@ -21,7 +42,7 @@ type specSchedule struct {
 func (s *specSchedule) Next(t time.Time) time.Time {
 	// General approach
 	//
-	// For Month, Day, Hour, Minute, Second:
+	// For Year, Month, Day, Hour, Minute, Second:
 	// Check if the time value matches.  If yes, continue to the next field.
 	// If the field doesn't match the schedule, then increment the field until it matches.
 	// While incrementing the field, a wrap-around brings it back to the beginning
@@ -48,14 +69,24 @@ func (s *specSchedule) Next(t time.Time) time.Time {
 	// This flag indicates whether a field has been incremented.
 	added := false
 
-	// If no time is found within five years, return zero.
+	// If no time is found within five years or the years are exhausted, return zero.
 	yearLimit := t.Year() + 5
+	if len(s.Year) > 0 {
+		yearLimit = s.Year[len(s.Year)-1]
+	}
 
 WRAP:
 	if t.Year() > yearLimit {
 		return time.Time{}
 	}
 
+	// Find the first applicable year.
+	// If it's this year, then do nothing.
+	if year := s.nextYear(t.Year()); year != t.Year() {
+		added = true
+		t = time.Date(year, time.January, 1, 0, 0, 0, 0, loc)
+	}
+
 	// Find the first applicable month.
 	// If it's this month, then do nothing.
 	for 1<<uint(t.Month())&s.Month == 0 {
@@ -138,15 +169,337 @@ WRAP:
 	return t.In(origLocation)
 }
 
+// Prev returns the previous time this schedule is activated, less than the given
+// time.  If no time can be found to satisfy the schedule, return the zero time.
+func (s *specSchedule) Prev(t time.Time) time.Time {
+	// General approach
+	//
+	// The same as Next but in reverse order. If the field doesn't match the
+	// schedule, then decrement the field to the last second of the previous
+	// value until it matches.
+
+	origLocation := t.Location()
+	loc := s.Location
+	if loc == time.Local {
+		loc = t.Location()
+	}
+
+	if s.Location != time.Local && s.Location != t.Location() {
+		t = t.In(s.Location)
+	}
+
+	// Start at the latest possible time (the previous second).
+	if t.Nanosecond() > 0 {
+		t = t.Add(-time.Duration(t.Nanosecond()) * time.Nanosecond)
+	} else {
+		t = t.Add(-1 * time.Second)
+	}
+
+	// If no time is found within five years or the years are exhausted, return zero.
+	yearLimit := t.Year() - 5
+	if len(s.Year) > 0 {
+		yearLimit = s.Year[0]
+	}
+
+WRAP:
+	if t.Year() < yearLimit {
+		return time.Time{}
+	}
+
+	// Find the last applicable year.
+	// If it's this year, then do nothing.
+	if year := s.prevYear(t.Year()); year != t.Year() {
+		t = time.Date(year+1, time.January, 1, 0, 0, 0, 0, loc).Add(-1 * time.Second)
+	}
+
+	for 1<<uint(t.Month())&s.Month == 0 {
+		t = time.Date(t.Year(), t.Month(), 1, 0, 0, 0, 0, loc).Add(-1 * time.Second)
+
+		// Wrapped around.
+		if t.Month() == time.December {
+			goto WRAP
+		}
+	}
+
+	for !s.dayMatches(t) {
+		month := t.Month()
+		t = time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, loc).Add(-1 * time.Second)
+
+		if t.Month() != month {
+			goto WRAP
+		}
+	}
+
+	for 1<<uint(t.Hour())&s.Hour == 0 {
+		day := t.Day()
+		t = time.Date(t.Year(), t.Month(), t.Day(), t.Hour(), 0, 0, 0, loc).Add(-1 * time.Second)
+
+		if t.Day() != day {
+			goto WRAP
+		}
+	}
+
+	for 1<<uint(t.Minute())&s.Minute == 0 {
+		t = t.Truncate(time.Minute).Add(-1 * time.Second)
+
+		if t.Minute() == 59 {
+			goto WRAP
+		}
+	}
+
+	for 1<<uint(t.Second())&s.Second == 0 {
+		t = t.Add(-1 * time.Second)
+
+		if t.Second() == 59 {
+			goto WRAP
+		}
+	}
+
+	return t.In(origLocation)
+}
+
+// prevYear returns the last year of schedule that not after the given year.
+// It must be called with the year not before the first year of schedule.
+func (s *specSchedule) prevYear(year int) int {
+	if len(s.Year) == 0 {
+		return year
+	}
+	return s.Year[sort.SearchInts(s.Year, year+1)-1]
+}
+
+// nextYear returns the first year of schedule that not before the given year.
+// It must be called with the year not after the last year of schedule.
+func (s *specSchedule) nextYear(year int) int {
+	if len(s.Year) == 0 {
+		return year
+	}
+	return s.Year[sort.SearchInts(s.Year, year)]
+}
+
 // dayMatches returns true if the schedule's day-of-week and day-of-month
 // restrictions are satisfied by the given time.
 func (s *specSchedule) dayMatches(t time.Time) bool {
 	var (
-		domMatch = 1<<uint(t.Day())&s.Dom > 0
-		dowMatch = 1<<uint(t.Weekday())&s.Dow > 0
+		domMatch = 1<<uint(t.Day())&s.Dom > 0 || s.domExtMatches(t)
+		dowMatch = 1<<uint(t.Weekday())&s.Dow > 0 || s.dowExtMatches(t)
 	)
 	if s.Dom&starBit > 0 || s.Dow&starBit > 0 {
 		return domMatch && dowMatch
 	}
 	return domMatch || dowMatch
 }
+
+// domExtMatches returns true if the Quartz-style day of month are satisfied by the given time.
+func (s *specSchedule) domExtMatches(t time.Time) bool {
+	if !s.DomLast && !s.DomLastWeekday && s.DomWeekday == 0 {
+		return false
+	}
+	day := t.Day()
+	last := daysIn(t.Year(), t.Month())
+	if s.DomLast && day == last {
+		return true
+	}
+	if s.DomLastWeekday && day == nearestWeekday(t.Year(), t.Month(), last) {
+		return true
+	}
+	// The nearest weekday is at most 2 days away from the given day.
+	for n := day - 2; n <= day+2; n++ {
+		if n < 1 || n > last || 1<<uint(n)&s.DomWeekday == 0 {
+			continue
+		}
+		if day == nearestWeekday(t.Year(), t.Month(), n) {
+			return true
+		}
+	}
+	return false
+}
+
+// dowExtMatches returns true if the Quartz-style day of week are satisfied by the given time.
+func (s *specSchedule) dowExtMatches(t time.Time) bool {
+	if s.DowLast == 0 && s.DowNth == 0 {
+		return false
+	}
+	weekday := uint(t.Weekday())
+	if 1<<weekday&s.DowLast > 0 && t.Day()+7 > daysIn(t.Year(), t.Month()) {
+		return true
+	}
+	nth := uint(t.Day()-1)/7 + 1
+	return 1<<(weekday*8+nth)&s.DowNth > 0
+}
+
+// daysIn returns the number of days in the month.
+func daysIn(year int, month time.Month) int {
+	return time.Date(year, month+1, 0, 0, 0, 0, 0, time.UTC).Day()
+}
+
+// nearestWeekday returns the weekday (Monday to Friday) nearest to the given day
+// in the same month.
+func nearestWeekday(year int, month time.Month, day int) int {
+	switch time.Date(year, month, day, 0, 0, 0, 0, time.UTC).Weekday() {
+	case time.Saturday:
+		if day == 1 {
+			return day + 2
+		}
+		return day - 1
+	case time.Sunday:
+		if day == daysIn(year, month) {
+			return day - 2
+		}
+		return day + 1
+	}
+	return day
+}
+
+// String returns the minimal canonical expression of the schedule. It has the
+// fields second, minute, hour, day of month, month, day of week and the year
+// if set, and a "TZ=" prefix if the location is not time.Local.
+// Equal schedules always return the same expression.
+func (s *specSchedule) String() string {
+	return s.format(s.options)
+}
+
+// allFields is the options to format all the fields.
+const allFields = Second | Minute | Hour | Dom | Month | Dow | YearOptional
+
+// format returns the expression of the fields in options, so that it can be
+// parsed by the Parser created with the same options.
+func (s *specSchedule) format(options Option) string {
+	if options&(allFields|Year) == 0 {
+		options = allFields
+	}
+
+	var fields []string
+	if options&Second > 0 {
+		fields = append(fields, formatBits(s.Second, secondBonds, false))
+	}
+	if options&Minute > 0 {
+		fields = append(fields, formatBits(s.Minute, minuteBounds, false))
+	}
+	if options&Hour > 0 {
+		fields = append(fields, formatBits(s.Hour, hourBounds, false))
+	}
+	if options&Dom > 0 {
+		fields = append(fields, s.formatDom())
+	}
+	if options&Month > 0 {
+		fields = append(fields, formatBits(s.Month, monthBounds, false))
+	}
+	if options&Dow > 0 {
+		fields = append(fields, s.formatDow())
+	}
+	if len(s.Year) > 0 && options&(Year|YearOptional) > 0 {
+		years := make([]uint, 0, len(s.Year))
+		for _, year := range s.Year {
+			years = append(years, uint(year))
+		}
+		fields = append(fields, formatValues(years, yearBounds))
+	} else if options&Year > 0 {
+		fields = append(fields, "*")
+	}
+
+	spec := strings.Join(fields, " ")
+	if s.Location != time.Local {
+		spec = "TZ=" + s.Location.String() + " " + spec
+	}
+	return spec
+}
+
+// formatDom returns the expression of day of month field.
+func (s *specSchedule) formatDom() string {
+	var items []string
+	if s.Dom != 0 {
+		items = append(items, formatBits(s.Dom, domBounds, true))
+	}
+	if s.DomLast {
+		items = append(items, "L")
+	}
+	if s.DomLastWeekday {
+		items = append(items, "LW")
+	}
+	for day := domBounds.min; day <= domBounds.max; day++ {
+		if 1<<day&s.DomWeekday > 0 {
+			items = append(items, strconv.Itoa(int(day))+"W")
+		}
+	}
+	return strings.Join(items, ",")
+}
+
+// formatDow returns the expression of day of week field.
+func (s *specSchedule) formatDow() string {
+	var items []string
+	if s.Dow != 0 {
+		items = append(items, formatBits(s.Dow, dowBounds, true))
+	}
+	for weekday := dowBounds.min; weekday <= dowBounds.max; weekday++ {
+		if 1<<weekday&s.DowLast > 0 {
+			items = append(items, strconv.Itoa(int(weekday))+"L")
+		}
+	}
+	for weekday := dowBounds.min; weekday <= dowBounds.max; weekday++ {
+		for nth := uint(1); nth <= 5; nth++ {
+			if 1<<(weekday*8+nth)&s.DowNth > 0 {
+				items = append(items, strconv.Itoa(int(weekday))+"#"+strconv.Itoa(int(nth)))
+			}
+		}
+	}
+	return strings.Join(items, ",")
+}
+
+// formatBits returns the expression of the bits within the given bounds.
+// The star is only kept for the day fields since it matters in dayMatches.
+func formatBits(bits uint64, r bounds, day bool) string {
+	if bits&starBit > 0 {
+		return "*"
+	}
+	var values []uint
+	for i := r.min; i <= r.max; i++ {
+		if 1<<i&bits > 0 {
+			values = append(values, i)
+		}
+	}
+	if !day && len(values) == int(r.max-r.min+1) {
+		return "*"
+	}
+	return formatValues(values, r)
+}
+
+// formatValues returns the minimal expression of the ascending values within
+// the given bounds, e.g. "*/15", "5/10", "1-10/3" or "1,3-5".
+func formatValues(values []uint, r bounds) string {
+	if len(values) >= 3 {
+		first, last := values[0], values[len(values)-1]
+		step := values[1] - first
+		progression := step > 1
+		for i := 2; progression && i < len(values); i++ {
+			progression = values[i]-values[i-1] == step
+		}
+		if progression {
+			switch {
+			case first == r.min && last+step > r.max:
+				return "*/" + strconv.Itoa(int(step))
+			case last+step > r.max:
+				return strconv.Itoa(int(first)) + "/" + strconv.Itoa(int(step))
+			default:
+				return strconv.Itoa(int(first)) + "-" + strconv.Itoa(int(last)) + "/" + strconv.Itoa(int(step))
+			}
+		}
+	}
+
+	// Joins the consecutive runs, the runs less than 3 values are listed.
+	var items []string
+	for i := 0; i < len(values); {
+		j := i
+		for j+1 < len(values) && values[j+1] == values[j]+1 {
+			j++
+		}
+		if j-i >= 2 {
+			items = append(items, strconv.Itoa(int(values[i]))+"-"+strconv.Itoa(int(values[j])))
+		} else {
+			for k := i; k <= j; k++ {
+				items = append(items, strconv.Itoa(int(values[k])))
+			}
+		}
+		i = j + 1
+	}
+	return strings.Join(items, ",")
+}
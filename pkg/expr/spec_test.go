@@ -1,6 +1,7 @@
 package expr
 
 import (
+	"fmt"
 	"strings"
 	"testing"
 	"time"
@@ -110,6 +111,33 @@ func TestExpr_Next(t *testing.T) {
 		// Leap year
 		{"Mon Jul 9 23:35 2012", "0 0 0 29 Feb ?", "Mon Feb 29 00:00 2016"},
 
+		// Last day of month
+		{"Mon Jul 9 23:35 2012", "0 0 0 L * ?", "Tue Jul 31 00:00 2012"},
+		{"Tue Jul 31 00:00 2012", "0 0 0 L * ?", "Fri Aug 31 00:00 2012"},
+		{"Mon Jan 30 00:00 2012", "0 0 0 L Feb ?", "Wed Feb 29 00:00 2012"},
+		{"Mon Jul 9 23:35 2012", "0 0 0 1,L * ?", "Tue Jul 31 00:00 2012"},
+
+		// Nearest weekday
+		{"Mon Jul 9 23:35 2012", "0 0 0 15W * ?", "Mon Jul 16 00:00 2012"},
+		{"Mon Jul 9 23:35 2012", "0 0 0 14W * ?", "Fri Jul 13 00:00 2012"},
+		{"Mon Jul 9 23:35 2012", "0 0 0 1W Sep ?", "Mon Sep 3 00:00 2012"},
+		{"Mon Jul 9 23:35 2012", "0 0 0 30W Sep ?", "Fri Sep 28 00:00 2012"},
+
+		// Last weekday of month
+		{"Mon Jul 9 23:35 2012", "0 0 0 LW Sep ?", "Fri Sep 28 00:00 2012"},
+		{"Mon Jul 9 23:35 2012", "0 0 0 LW * ?", "Tue Jul 31 00:00 2012"},
+
+		// Nth day of week
+		{"Mon Jul 9 23:35 2012", "0 0 0 ? * 5#3", "Fri Jul 20 00:00 2012"},
+		{"Mon Jul 9 23:35 2012", "0 0 0 ? * Mon#2", "Mon Aug 13 00:00 2012"},
+		{"Mon Jul 9 23:35 2012", "0 0 0 ? * 1#5", "Mon Jul 30 00:00 2012"},
+		{"Mon Jul 30 00:00 2012", "0 0 0 ? * 1#5", "Mon Oct 29 00:00 2012"},
+
+		// Last day of week
+		{"Mon Jul 9 23:35 2012", "0 0 0 ? * 5L", "Fri Jul 27 00:00 2012"},
+		{"Mon Jul 9 23:35 2012", "0 0 0 ? * FriL", "Fri Jul 27 00:00 2012"},
+		{"Mon Jul 9 23:35 2012", "0 0 0 ? * L", "Sat Jul 28 00:00 2012"},
+
 		// Daylight savings time 2am EST (-5) -> 3am EDT (-4)
 		{"2012-03-11T00:00:00-0500", "TZ=America/New_York 0 30 2 11 Mar ?", "2013-03-11T02:30:00-0400"},
 
@@ -199,12 +227,245 @@ func TestExpr_Next(t *testing.T) {
 	}
 }
 
+func TestExpr_NextYear(t *testing.T) {
+	parser := New(Second | Minute | Hour | Dom | Month | Dow | YearOptional)
+	runs := []struct {
+		time, spec string
+		expected   string
+	}{
+		{"Mon Jul 9 23:35 2012", "0 0 0 1 1 * 2014-2015", "Wed Jan 1 00:00 2014"},
+		{"Wed Jan 1 00:00 2014", "0 0 0 1 1 * 2014-2015", "Thu Jan 1 00:00 2015"},
+		{"Thu Jan 1 00:00 2015", "0 0 0 1 1 * 2014-2015", ""},
+		{"Mon Jul 9 23:35 2012", "0 30 * * * * 2012", "Tue Jul 10 00:30 2012"},
+		{"Mon Dec 31 23:59:45 2012", "0 * * * * * 2012", ""},
+		{"Mon Jul 9 23:35 2012", "0 0 0 1 1 * 2012/10", "Sat Jan 1 00:00 2022"},
+		{"Mon Jul 9 23:35 2012", "0 0 0 1 1 * 2050", "Sat Jan 1 00:00 2050"},
+		{"Mon Jul 9 23:35 2012", "0 0 0 29 Feb * 2013-2015", ""},
+		{"Mon Jul 9 23:35 2012", "0 0 0 29 Feb * 2013-2016", "Mon Feb 29 00:00 2016"},
+		{"Mon Jul 9 23:35 2012", "0 0 0 1 1 *", "Tue Jan 1 00:00 2013"},
+	}
+	for _, c := range runs {
+		sched, err := parser.Parse(c.spec)
+		if err != nil {
+			t.Error(err)
+			continue
+		}
+		actual := sched.Next(getTime(c.time))
+		expected := getTime(c.expected)
+		if !actual.Equal(expected) {
+			t.Errorf("%s, \"%s\": (expected) %v != %v (actual)", c.time, c.spec, expected, actual)
+		}
+	}
+}
+
+func TestExpr_Prev(t *testing.T) {
+	parser := New(Second | Minute | Hour | Dom | Month | Dow | YearOptional | Descriptor)
+	runs := []struct {
+		time, spec string
+		expected   string
+	}{
+		{"Mon Jul 9 15:00 2012", "0 0/15 * * * *", "Mon Jul 9 14:45 2012"},
+		{"Mon Jul 9 15:00:01 2012", "0 0/15 * * * *", "Mon Jul 9 15:00 2012"},
+		{"Mon Jul 9 14:59:59 2012", "0 0/15 * * * *", "Mon Jul 9 14:45 2012"},
+
+		// Wrap around hours, days, months and years
+		{"Mon Jul 9 15:10 2012", "0 20-35/15 * * * *", "Mon Jul 9 14:35 2012"},
+		{"Tue Jul 10 00:10 2012", "0 20-35/15 * * * *", "Mon Jul 9 23:35 2012"},
+		{"Mon Jul 9 00:00 2012", "0 0 0 9 Apr-Oct ?", "Sat Jun 9 00:00 2012"},
+		{"Mon Jul 9 23:35 2012", "0 0 0 * Feb Mon", "Mon Feb 27 00:00 2012"},
+		{"Tue Jan 1 00:00:00 2013", "* * * * * *", "Mon Dec 31 23:59:59 2012"},
+
+		// Leap year
+		{"Mon Jul 9 23:35 2012", "0 0 0 29 Feb ?", "Wed Feb 29 00:00 2012"},
+		{"Mon Jul 9 23:35 2013", "0 0 0 29 Feb ?", "Wed Feb 29 00:00 2012"},
+
+		// Quartz-style day fields
+		{"Mon Jul 9 23:35 2012", "0 0 0 L * ?", "Sat Jun 30 00:00 2012"},
+		{"Mon Jul 9 23:35 2012", "0 0 0 ? * 5#3", "Fri Jun 15 00:00 2012"},
+
+		// Year field
+		{"Mon Jul 9 23:35 2012", "0 0 0 1 1 * 2008-2010", "Fri Jan 1 00:00 2010"},
+		{"Mon Jul 9 23:35 2012", "0 0 0 1 1 * 2013", ""},
+
+		// Daylight savings time 2am EST (-5) -> 3am EDT (-4)
+		{"2012-03-11T04:00:00-0400", "TZ=America/New_York 0 0 * * * ?", "2012-03-11T03:00:00-0400"},
+		{"2012-03-11T03:00:00-0400", "TZ=America/New_York 0 0 * * * ?", "2012-03-11T01:00:00-0500"},
+
+		// Unsatisfiable
+		{"Mon Jul 9 23:35 2012", "0 0 0 30 Feb ?", ""},
+	}
+
+	for _, c := range runs {
+		sched, err := parser.Parse(c.spec)
+		if err != nil {
+			t.Error(err)
+			continue
+		}
+		actual := sched.(ReverseSchedule).Prev(getTime(c.time))
+		expected := getTime(c.expected)
+		if !actual.Equal(expected) {
+			t.Errorf("%s, \"%s\": (expected) %v != %v (actual)", c.time, c.spec, expected, actual)
+		}
+	}
+}
+
+func TestExpr_PrevNext(t *testing.T) {
+	specs := []string{
+		"0 0/15 * * * *",
+		"15/35 20-35/15 1/2 */2 * *",
+		"0 0 0 */5 Apr,Aug,Oct Mon",
+		"0 30 9 15W * ?",
+		"0 0 12 ? * 1L",
+	}
+	start := getTime("Mon Jul 9 23:35:51 2012")
+	for _, spec := range specs {
+		sched, err := secondParser.Parse(spec)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for i := 0; i < 100; i++ {
+			now := start.Add(time.Duration(i) * 7 * time.Hour)
+			prev := sched.(ReverseSchedule).Prev(now)
+			if !prev.Before(now) || sched.Next(prev.Add(-time.Second)) != prev || sched.Next(prev).Before(now) {
+				t.Errorf("%s, %s: wrong previous time %s", spec, now, prev)
+			}
+		}
+	}
+}
+
+func TestExpr_String(t *testing.T) {
+	parser := New(Second | Minute | Hour | Dom | Month | Dow | YearOptional | Descriptor)
+	tests := []struct {
+		spec, expected string
+	}{
+		{"0 0/15 * * * *", "0 */15 * * * *"},
+		{"0 0-59/15 * * * *", "0 */15 * * * *"},
+		{"0 5/15 * * * *", "0 5/15 * * * *"},
+		{"0 5-40/15 * * * *", "0 5-35/15 * * * *"},
+		{"0 0 0-23 * * *", "0 0 * * * *"},
+		{"0 0 0 1-31 * *", "0 0 0 1-31 * *"},
+		{"0 0 0 ? * ?", "0 0 0 * * *"},
+		{"0 0 0 * Jan-Mar,Jul Mon-Fri", "0 0 0 * 1-3,7 1-5"},
+		{"0 0 0 1,2,4 * *", "0 0 0 1,2,4 * *"},
+		{"0 0 0 L,15W,LW * ?", "0 0 0 L,LW,15W * *"},
+		{"0 0 0 ? * FriL,Mon#2,L", "0 0 0 * * 5L,6L,1#2"},
+		{"0 0 0 1 1 * 2027-2030", "0 0 0 1 1 * 2027-2030"},
+		{"0 0 0 1 1 * */10", "0 0 0 1 1 * */10"},
+		{"TZ=Asia/Tokyo 0 0 0 * * *", "TZ=Asia/Tokyo 0 0 0 * * *"},
+		{"@daily", "0 0 0 * * *"},
+		{"@every 90m", "@every 1h30m0s"},
+	}
+	for _, c := range tests {
+		sched, err := parser.Parse(c.spec)
+		if err != nil {
+			t.Error(err)
+			continue
+		}
+		actual := sched.(fmt.Stringer).String()
+		if actual != c.expected {
+			t.Errorf("%s => expected %q, got %q", c.spec, c.expected, actual)
+		}
+
+		// The canonical expression can be parsed to an equal schedule.
+		reparsed, err := parser.Parse(actual)
+		if err != nil {
+			t.Errorf("%s => unexpected error %v", actual, err)
+			continue
+		}
+		if !Equal(sched, reparsed) {
+			t.Errorf("%s => expected %v, got %v", actual, sched, reparsed)
+		}
+	}
+}
+
+func TestExpr_StringRoundTrip(t *testing.T) {
+	unixCron := New(Minute | Hour | Dom | Month | Dow | YearOptional | Descriptor)
+	withYear := New(Second | Minute | Hour | Dom | Month | Dow | Year | Descriptor)
+	tests := []struct {
+		parser         Parser
+		spec, expected string
+	}{
+		{Standard, "0-59/15 * * * *", "*/15 * * * *"},
+		{Standard, "0 0 L,15W * ?", "0 0 L,15W * *"},
+		{Standard, "0 0 ? * FriL,Mon#2", "0 0 * * 5L,1#2"},
+		{Standard, "0 0 1,2,4 Jan-Mar,Jul *", "0 0 1,2,4 1-3,7 *"},
+		{Standard, "TZ=Asia/Tokyo 30 9 * * Mon-Fri", "TZ=Asia/Tokyo 30 9 * * 1-5"},
+		{Standard, "@daily", "0 0 * * *"},
+		{Standard, "@every 90m", "@every 1h30m0s"},
+		{unixCron, "*/15 * * * *", "*/15 * * * *"},
+		{unixCron, "0 0 1 1 * 2027-2030", "0 0 1 1 * 2027-2030"},
+		{unixCron, "@monthly", "0 0 1 * *"},
+		{WithSeconds, "*/20 */15 * * * *", "*/20 */15 * * * *"},
+		{WithSeconds, "TZ=UTC 0 0 0 LW * *", "TZ=UTC 0 0 0 LW * *"},
+		{withYear, "0 0 0 1 1 * 2027-2030,2040", "0 0 0 1 1 * 2027-2030,2040"},
+		{withYear, "0 0 0 1 1 * *", "0 0 0 1 1 * *"},
+		{withYear, "@yearly", "0 0 0 1 1 * *"},
+		{New(Hour | Dom | Month), "9 1 */3", "9 1 */3"},
+	}
+	for _, c := range tests {
+		sched, err := c.parser.Parse(c.spec)
+		if err != nil {
+			t.Error(err)
+			continue
+		}
+		actual := sched.(fmt.Stringer).String()
+		if actual != c.expected {
+			t.Errorf("%s => expected %q, got %q", c.spec, c.expected, actual)
+		}
+
+		reparsed, err := c.parser.Parse(actual)
+		if err != nil {
+			t.Errorf("%s => unexpected error %v", actual, err)
+			continue
+		}
+		if !Equal(sched, reparsed) {
+			t.Errorf("%s => expected %v, got %v", actual, sched, reparsed)
+		}
+	}
+}
+
+func TestExpr_Equal(t *testing.T) {
+	tests := []struct {
+		a, b     string
+		expected bool
+	}{
+		{"0-59/15 * * * *", "*/15 * * * *", true},
+		{"0 0 * * mon-fri", "0 0 * * 1,2,3,4,5", true},
+		{"@daily", "0 0 * * *", true},
+		{"@every 1h", "@every 60m", true},
+		{"0 0 * * *", "0 0 * * 1", false},
+		{"0 0 * * *", "TZ=UTC 0 0 * * *", false},
+		{"0 0 * * *", "@every 24h", false},
+	}
+	for _, c := range tests {
+		a, err := Standard.Parse(c.a)
+		if err != nil {
+			t.Fatal(err)
+		}
+		b, err := Standard.Parse(c.b)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if actual := Equal(a, b); actual != c.expected {
+			t.Errorf("%s, %s => expected %v, got %v", c.a, c.b, c.expected, actual)
+		}
+	}
+}
+
 func TestExpr_Errors(t *testing.T) {
 	invalidSpecs := []string{
 		"xyz",
 		"60 0 * * *",
 		"0 60 * * *",
 		"0 0 * * XYZ",
+		"0 0 32W * *",
+		"0 0 0W * *",
+		"0 0 * * 7L",
+		"0 0 * * 5#6",
+		"0 0 * * 5#0",
+		"0 0 * * 5#3#1",
+		"0 0 * * XYZ#1",
+		"0 0 1 1 * *",
 	}
 	for _, spec := range invalidSpecs {
 		_, err := Standard.Parse(spec)
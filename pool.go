@@ -0,0 +1,339 @@
+package cron
+
+import (
+	"container/heap"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Priority is the priority of a job submitted with WithPriority. The runs with
+// higher priority are taken from the queue of worker pool first, and the runs
+// with the same priority are taken in order of fired. Any integer is allowed.
+type Priority int
+
+const (
+	// PriorityLow is the priority of the jobs that can wait, e.g. analytics.
+	// The runs with priority lower than PriorityNormal can be dropped if they
+	// have waited too long, see WithStaleness.
+	PriorityLow Priority = -100
+
+	// PriorityNormal is the default priority.
+	PriorityNormal Priority = 0
+
+	// PriorityHigh is the priority of the jobs that should not wait, e.g. billing.
+	PriorityHigh Priority = 100
+)
+
+// OverflowPolicy decides how to handle the run that fired when the queue of
+// worker pool is full, see WithWorkerPool.
+type OverflowPolicy int
+
+const (
+	// OverflowBlock waits for the room of queue, the waiting runs are queued in
+	// order of priority. The waiting run is skipped if the Crontab stops, it is
+	// reported as EventRunSkipped.
+	OverflowBlock OverflowPolicy = iota
+
+	// OverflowDrop skips the run with the lowest priority among the run and
+	// the queued runs, it is reported as EventRunSkipped.
+	OverflowDrop
+
+	// OverflowRunInline runs the job in the goroutine of Driver that fired the run.
+	OverflowRunInline
+)
+
+// PoolStats is a snapshot of the worker pool set with WithWorkerPool.
+type PoolStats struct {
+	// Workers is the number of workers.
+	Workers int
+
+	// Busy is the number of workers running a job.
+	Busy int
+
+	// Queued is the number of runs waiting in the queue.
+	Queued int
+
+	// Dispatched is the number of runs taken from the queue by workers.
+	Dispatched int64
+
+	// Dropped is the number of runs skipped by OverflowDrop, or by OverflowBlock
+	// when the pool stopped.
+	Dropped int64
+
+	// Stale is the number of runs skipped since waited longer than the staleness.
+	Stale int64
+
+	// Inlined is the number of runs run inline by OverflowRunInline.
+	Inlined int64
+
+	// WaitTime is the total time of the dispatched runs waiting in the queue.
+	// The average wait is WaitTime / Dispatched.
+	WaitTime time.Duration
+
+	// MaxWaitTime is the longest time of a dispatched run waiting in the queue.
+	MaxWaitTime time.Duration
+}
+
+// poolTask is a run waiting in the queue of workerPool.
+type poolTask struct {
+	fn       func()
+	drop     func() // called instead of fn if the task is dropped.
+	priority Priority
+	enqueued time.Time
+	seq      uint64 // the sequence of the tasks submitted.
+	index    int    // the index in the heap.
+}
+
+// workerPool runs the fired runs on a fixed number of goroutines. The tasks
+// wait in a priority queue for a free worker.
+type workerPool struct {
+	// The counters updated atomically, they are placed first to be 64-bit
+	// aligned on 32-bit platforms.
+	dispatched int64
+	dropped    int64
+	inlined    int64
+	stale      int64
+	waitTime   int64
+	maxWait    int64
+	busy       int32
+
+	workers   int
+	size      int
+	overflow  OverflowPolicy
+	staleness time.Duration    // the max wait of the low priority tasks, 0 means no limited.
+	now       func() time.Time // the Clock of Crontab to measure the wait.
+
+	// The fields below are protected by mu.
+	mu       *sync.Mutex
+	notEmpty *sync.Cond // signaled when a task is pushed or the workers stop.
+	notFull  *sync.Cond // broadcast when a task is popped, a worker is idle or the workers stop.
+	tasks    poolTasks
+	waiting  poolTasks // the tasks blocked by OverflowBlock.
+	seq      uint64
+	idle     int  // the number of workers waiting for tasks.
+	started  bool // whether the workers are started.
+	gen      int  // increased each time the workers start or stop.
+}
+
+func newWorkerPool(workers int, queueSize int) *workerPool {
+	if workers <= 0 {
+		panic("cron: WithWorkerPool: the number of workers must be greater than 0")
+	}
+	if queueSize < 0 {
+		panic("cron: WithWorkerPool: the queue size cannot be negative")
+	}
+	mu := new(sync.Mutex)
+	return &workerPool{
+		workers:   workers,
+		size:      queueSize,
+		overflow:  OverflowBlock,
+		staleness: 0,
+		now:       time.Now,
+		mu:        mu,
+		notEmpty:  sync.NewCond(mu),
+		notFull:   sync.NewCond(mu),
+		tasks:     nil,
+		waiting:   nil,
+		seq:       0,
+		idle:      0,
+		started:   false,
+		gen:       0,
+	}
+}
+
+// start starts the workers if not started.
+func (p *workerPool) start() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.started {
+		return
+	}
+	p.started = true
+	p.gen++
+	for i := 0; i < p.workers; i++ {
+		go p.work(p.gen)
+	}
+}
+
+// stop stops the workers after their running jobs completed. The tasks in the
+// queue are kept until started again, and the blocked submits give up.
+func (p *workerPool) stop() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if !p.started {
+		return
+	}
+	p.started = false
+	p.gen++
+	p.notEmpty.Broadcast()
+	p.notFull.Broadcast()
+}
+
+func (p *workerPool) work(gen int) {
+	p.mu.Lock()
+	for {
+		for p.gen == gen && len(p.tasks) == 0 {
+			p.idle++
+			// The idle worker makes room for a blocked submit.
+			p.wakeWaiting()
+			p.notEmpty.Wait()
+			p.idle--
+		}
+		if p.gen != gen {
+			p.mu.Unlock()
+			return
+		}
+		task := heap.Pop(&p.tasks).(*poolTask)
+		p.wakeWaiting()
+		p.mu.Unlock()
+
+		wait := int64(p.now().Sub(task.enqueued))
+		if task.priority < PriorityNormal && p.staleness > 0 && wait > int64(p.staleness) {
+			atomic.AddInt64(&p.stale, 1)
+			task.drop()
+		} else {
+			atomic.AddInt64(&p.dispatched, 1)
+			atomic.AddInt64(&p.waitTime, wait)
+			for max := atomic.LoadInt64(&p.maxWait); wait > max; max = atomic.LoadInt64(&p.maxWait) {
+				if atomic.CompareAndSwapInt64(&p.maxWait, max, wait) {
+					break
+				}
+			}
+
+			atomic.AddInt32(&p.busy, 1)
+			task.fn()
+			atomic.AddInt32(&p.busy, -1)
+		}
+		p.mu.Lock()
+	}
+}
+
+// wakeWaiting wakes the blocked submits if any, the one with highest priority
+// takes the room. It must be called with p.mu held.
+func (p *workerPool) wakeWaiting() {
+	if len(p.waiting) != 0 {
+		p.notFull.Broadcast()
+	}
+}
+
+// full reports whether no room for a task. The idle workers take the tasks
+// immediately, so the queue of size 0 accepts the tasks for them.
+// It must be called with p.mu held.
+func (p *workerPool) full() bool {
+	return len(p.tasks)-p.idle >= p.size
+}
+
+// submit puts the fn into the queue with the priority, the fn submitted while
+// the queue is full is handled by the OverflowPolicy. The drop is called if the
+// fn is dropped, it may be called before submit returns.
+func (p *workerPool) submit(priority Priority, fn func(), drop func()) {
+	p.mu.Lock()
+	p.seq++
+	task := &poolTask{fn: fn, drop: drop, priority: priority, enqueued: p.now(), seq: p.seq}
+	if !p.full() && len(p.waiting) == 0 {
+		heap.Push(&p.tasks, task)
+		p.notEmpty.Signal()
+		p.mu.Unlock()
+		return
+	}
+
+	switch p.overflow {
+	case OverflowDrop:
+		// Drops the queued task with the lowest priority if lower than the task.
+		if lowest := p.tasks.lowest(); lowest >= 0 && p.tasks[lowest].priority < priority {
+			dropped := p.tasks[lowest]
+			heap.Remove(&p.tasks, lowest)
+			heap.Push(&p.tasks, task)
+			p.notEmpty.Signal()
+			task = dropped
+		}
+		p.mu.Unlock()
+		atomic.AddInt64(&p.dropped, 1)
+		task.drop()
+	case OverflowRunInline:
+		p.mu.Unlock()
+		atomic.AddInt64(&p.inlined, 1)
+		fn()
+	default:
+		heap.Push(&p.waiting, task)
+		for p.started && (p.full() || p.waiting[0] != task) {
+			p.notFull.Wait()
+		}
+		heap.Remove(&p.waiting, task.index)
+		if p.full() {
+			// Gives up since the workers stopped.
+			p.mu.Unlock()
+			atomic.AddInt64(&p.dropped, 1)
+			task.drop()
+			return
+		}
+		heap.Push(&p.tasks, task)
+		p.notEmpty.Signal()
+		p.wakeWaiting()
+		p.mu.Unlock()
+	}
+}
+
+// stats returns a snapshot of the pool.
+func (p *workerPool) stats() PoolStats {
+	p.mu.Lock()
+	queued := len(p.tasks)
+	p.mu.Unlock()
+	return PoolStats{
+		Workers:     p.workers,
+		Busy:        int(atomic.LoadInt32(&p.busy)),
+		Queued:      queued,
+		Dispatched:  atomic.LoadInt64(&p.dispatched),
+		Dropped:     atomic.LoadInt64(&p.dropped),
+		Stale:       atomic.LoadInt64(&p.stale),
+		Inlined:     atomic.LoadInt64(&p.inlined),
+		WaitTime:    time.Duration(atomic.LoadInt64(&p.waitTime)),
+		MaxWaitTime: time.Duration(atomic.LoadInt64(&p.maxWait)),
+	}
+}
+
+// poolTasks implements heap.Interface, the task with higher priority and then
+// the smaller sequence is popped first.
+type poolTasks []*poolTask
+
+func (h poolTasks) Len() int { return len(h) }
+
+func (h poolTasks) Less(i, j int) bool {
+	if h[i].priority != h[j].priority {
+		return h[i].priority > h[j].priority
+	}
+	return h[i].seq < h[j].seq
+}
+
+func (h poolTasks) Swap(i, j int) {
+	h[i], h[j] = h[j], h[i]
+	h[i].index = i
+	h[j].index = j
+}
+
+func (h *poolTasks) Push(x interface{}) {
+	task := x.(*poolTask)
+	task.index = len(*h)
+	*h = append(*h, task)
+}
+
+func (h *poolTasks) Pop() interface{} {
+	old := *h
+	n := len(old)
+	task := old[n-1]
+	old[n-1] = nil
+	*h = old[:n-1]
+	return task
+}
+
+// lowest returns the index of the task popped last, or -1 if empty.
+func (h poolTasks) lowest() int {
+	index := -1
+	for i := range h {
+		if index == -1 || h.Less(index, i) {
+			index = i
+		}
+	}
+	return index
+}
@@ -0,0 +1,331 @@
+package cron
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestWorkerPool(t *testing.T) {
+	pool := newWorkerPool(2, 1)
+	pool.start()
+	defer pool.stop()
+
+	var dropped int32
+	drop := func() { atomic.AddInt32(&dropped, 1) }
+	releaseC := make(chan struct{})
+	blocking := func() { <-releaseC }
+	for i := 0; i < 2; i++ {
+		pool.submit(PriorityNormal, blocking, drop)
+	}
+	require.Eventually(t, func() bool { return pool.stats().Busy == 2 }, time.Second, time.Millisecond)
+	pool.submit(PriorityNormal, blocking, drop)
+	require.Equal(t, 1, pool.stats().Queued)
+
+	// The queue is full.
+	pool.overflow = OverflowDrop
+	pool.submit(PriorityNormal, blocking, drop)
+	require.Equal(t, int32(1), atomic.LoadInt32(&dropped))
+
+	pool.overflow = OverflowRunInline
+	var inlined bool
+	pool.submit(PriorityNormal, func() { inlined = true }, drop)
+	require.True(t, inlined)
+
+	pool.overflow = OverflowBlock
+	doneC := make(chan struct{})
+	go func() {
+		pool.submit(PriorityNormal, func() {}, drop)
+		close(doneC)
+	}()
+	select {
+	case <-doneC:
+		t.Fatal("the submit is not blocked")
+	case <-time.After(time.Millisecond * 30):
+	}
+
+	close(releaseC)
+	<-doneC
+	require.Eventually(t, func() bool { return pool.stats().Dispatched == 4 }, time.Second, time.Millisecond)
+
+	stats := pool.stats()
+	require.Equal(t, 2, stats.Workers)
+	require.Equal(t, 0, stats.Queued)
+	require.Equal(t, int64(1), stats.Dropped)
+	require.Equal(t, int64(1), stats.Inlined)
+	require.True(t, stats.MaxWaitTime >= time.Millisecond*30)
+	require.True(t, stats.WaitTime >= stats.MaxWaitTime)
+	require.Equal(t, int32(1), atomic.LoadInt32(&dropped))
+
+	require.Panics(t, func() { newWorkerPool(0, 0) })
+	require.Panics(t, func() { newWorkerPool(1, -1) })
+}
+
+func TestWorkerPool_StopBlocked(t *testing.T) {
+	pool := newWorkerPool(1, 0)
+	pool.start()
+
+	releaseC := make(chan struct{})
+	defer close(releaseC)
+	pool.submit(PriorityNormal, func() { <-releaseC }, func() {})
+	require.Eventually(t, func() bool { return pool.stats().Busy == 1 }, time.Second, time.Millisecond)
+
+	// The submits are blocked since the only worker is busy.
+	var wg sync.WaitGroup
+	var ran, dropped int32
+	for i := 0; i < 3; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			pool.submit(PriorityNormal, func() { atomic.AddInt32(&ran, 1) }, func() { atomic.AddInt32(&dropped, 1) })
+		}()
+	}
+	time.Sleep(time.Millisecond * 30)
+	require.Equal(t, int32(0), atomic.LoadInt32(&dropped))
+
+	// The blocked submits give up on stop.
+	pool.stop()
+	wg.Wait()
+	require.Equal(t, int32(0), atomic.LoadInt32(&ran))
+	require.Equal(t, int32(3), atomic.LoadInt32(&dropped))
+	require.Equal(t, int64(3), pool.stats().Dropped)
+}
+
+func TestWorkerPool_Priority(t *testing.T) {
+	pool := newWorkerPool(1, 10)
+	defer pool.stop()
+
+	var mu sync.Mutex
+	var order []int
+	task := func(i int) func() {
+		return func() {
+			mu.Lock()
+			order = append(order, i)
+			mu.Unlock()
+		}
+	}
+	priorities := []Priority{PriorityLow, PriorityNormal, PriorityHigh, PriorityHigh, PriorityNormal, 1}
+	for i, priority := range priorities {
+		pool.submit(priority, task(i), func() {})
+	}
+
+	// The queued tasks are taken in order of priority once started.
+	pool.start()
+	require.Eventually(t, func() bool { return pool.stats().Dispatched == 6 }, time.Second, time.Millisecond)
+	mu.Lock()
+	require.Equal(t, []int{2, 3, 5, 1, 4, 0}, order)
+	mu.Unlock()
+}
+
+func TestWorkerPool_BlockPriority(t *testing.T) {
+	pool := newWorkerPool(1, 0)
+	pool.start()
+	defer pool.stop()
+
+	releaseC := make(chan struct{})
+	pool.submit(PriorityNormal, func() { <-releaseC }, func() {})
+	require.Eventually(t, func() bool { return pool.stats().Busy == 1 }, time.Second, time.Millisecond)
+
+	var mu sync.Mutex
+	var order []Priority
+	var wg sync.WaitGroup
+	waiting := func() int {
+		pool.mu.Lock()
+		defer pool.mu.Unlock()
+		return len(pool.waiting)
+	}
+	// The submits are blocked in order of low, normal and high.
+	for i, priority := range []Priority{PriorityLow, PriorityNormal, PriorityHigh} {
+		priority := priority
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			pool.submit(priority, func() {
+				mu.Lock()
+				order = append(order, priority)
+				mu.Unlock()
+			}, func() {})
+		}()
+		n := i + 1
+		require.Eventually(t, func() bool { return waiting() == n }, time.Second, time.Millisecond)
+	}
+
+	// The blocked submits are queued in order of priority.
+	close(releaseC)
+	wg.Wait()
+	require.Eventually(t, func() bool { return pool.stats().Dispatched == 4 }, time.Second, time.Millisecond)
+	mu.Lock()
+	defer mu.Unlock()
+	require.Equal(t, []Priority{PriorityHigh, PriorityNormal, PriorityLow}, order)
+}
+
+func TestWorkerPool_StalenessClock(t *testing.T) {
+	pool := newWorkerPool(1, 1)
+	pool.staleness = time.Minute
+	var mu sync.Mutex
+	now := time.Now()
+	pool.now = func() time.Time {
+		mu.Lock()
+		defer mu.Unlock()
+		return now
+	}
+	defer pool.stop()
+
+	var ran, dropped int32
+	run := func() { atomic.AddInt32(&ran, 1) }
+	drop := func() { atomic.AddInt32(&dropped, 1) }
+	pool.submit(PriorityLow, run, drop)
+
+	// The wait is measured by the clock of pool.
+	mu.Lock()
+	now = now.Add(time.Minute * 2)
+	mu.Unlock()
+	pool.start()
+	require.Eventually(t, func() bool { return atomic.LoadInt32(&dropped) == 1 }, time.Second, time.Millisecond)
+
+	pool.submit(PriorityLow, run, drop)
+	require.Eventually(t, func() bool { return atomic.LoadInt32(&ran) == 1 }, time.Second, time.Millisecond)
+	require.Equal(t, int64(1), pool.stats().Stale)
+}
+
+func TestWorkerPool_Drop(t *testing.T) {
+	pool := newWorkerPool(1, 2)
+	pool.overflow = OverflowDrop
+	pool.staleness = time.Millisecond * 20
+	defer pool.stop()
+
+	var mu sync.Mutex
+	var ran, dropped []string
+	submit := func(name string, priority Priority) {
+		pool.submit(priority, func() {
+			mu.Lock()
+			ran = append(ran, name)
+			mu.Unlock()
+		}, func() {
+			mu.Lock()
+			dropped = append(dropped, name)
+			mu.Unlock()
+		})
+	}
+	submit("analytics", PriorityLow)
+	submit("report", PriorityNormal)
+
+	// The queued task with lower priority is dropped.
+	submit("billing", PriorityHigh)
+	submit("cleanup", PriorityLow)
+	mu.Lock()
+	require.Equal(t, []string{"analytics", "cleanup"}, dropped)
+	mu.Unlock()
+
+	// The stale task with low priority is dropped.
+	pool.overflow = OverflowBlock
+	pool.mu.Lock()
+	pool.size = 3
+	pool.mu.Unlock()
+	submit("stale", PriorityLow)
+	time.Sleep(time.Millisecond * 30)
+	pool.start()
+	require.Eventually(t, func() bool {
+		mu.Lock()
+		defer mu.Unlock()
+		return len(ran)+len(dropped) == 5
+	}, time.Second, time.Millisecond)
+
+	mu.Lock()
+	defer mu.Unlock()
+	require.Equal(t, []string{"billing", "report"}, ran)
+	require.Equal(t, []string{"analytics", "cleanup", "stale"}, dropped)
+	require.Equal(t, int64(1), pool.stats().Stale)
+}
+
+func TestCrontab_WorkerPool(t *testing.T) {
+	var mu sync.Mutex
+	var skipped []string
+	listener := ListenerFunc(func(event Event) {
+		if event.Type == EventRunSkipped {
+			mu.Lock()
+			skipped = append(skipped, event.Key)
+			mu.Unlock()
+		}
+	})
+	cron := New(WithListener(listener), WithWorkerPool(1, 0), WithOverflowPolicy(OverflowDrop))
+	require.Nil(t, cron.Start())
+	defer cron.Stop()
+
+	startC := make(chan struct{})
+	releaseC := make(chan struct{})
+	var once sync.Once
+	blocking := JobFunc(func(ctx context.Context) error {
+		once.Do(func() { close(startC) })
+		<-releaseC
+		return nil
+	})
+	job := JobFunc(func(ctx context.Context) error { return nil })
+	require.Nil(t, cron.Submit(context.Background(), "job1", blocking, &Appoint{Time: time.Now().Add(time.Millisecond * 20)}))
+	<-startC
+
+	// The only worker is busy.
+	require.Nil(t, cron.Submit(context.Background(), "job2", job, &Appoint{Time: time.Now().Add(time.Millisecond * 20)}))
+	require.Eventually(t, func() bool {
+		mu.Lock()
+		defer mu.Unlock()
+		return len(skipped) == 1
+	}, time.Second, time.Millisecond*5)
+	close(releaseC)
+
+	mu.Lock()
+	require.Equal(t, []string{"job2"}, skipped)
+	mu.Unlock()
+
+	stats := cron.PoolStats()
+	require.Equal(t, 1, stats.Workers)
+	require.Equal(t, int64(1), stats.Dropped)
+	info, _ := cron.Get("job2")
+	require.Equal(t, int64(0), info.Runs)
+
+	require.Equal(t, PoolStats{}, New().PoolStats())
+}
+
+func TestCrontab_Priority(t *testing.T) {
+	cron := New(WithWorkerPool(1, 10))
+	require.Nil(t, cron.Start())
+	defer cron.Stop()
+
+	startC := make(chan struct{})
+	releaseC := make(chan struct{})
+	require.Nil(t, cron.Submit(context.Background(), "blocking", JobFunc(func(ctx context.Context) error {
+		close(startC)
+		<-releaseC
+		return nil
+	}), &Appoint{Time: time.Now().Add(time.Millisecond * 20)}))
+	<-startC
+
+	var mu sync.Mutex
+	var order []string
+	job := func(key string) Job {
+		return JobFunc(func(ctx context.Context) error {
+			mu.Lock()
+			order = append(order, key)
+			mu.Unlock()
+			return nil
+		})
+	}
+	at := time.Now().Add(time.Millisecond * 20)
+	require.Nil(t, cron.Submit(context.Background(), "analytics", job("analytics"), &Appoint{Time: at}, WithPriority(PriorityLow)))
+	require.Nil(t, cron.Submit(context.Background(), "billing", job("billing"), &Appoint{Time: at}, WithPriority(PriorityHigh)))
+	require.Eventually(t, func() bool { return cron.PoolStats().Queued == 2 }, time.Second, time.Millisecond)
+
+	close(releaseC)
+	require.Eventually(t, func() bool {
+		mu.Lock()
+		defer mu.Unlock()
+		return len(order) == 2
+	}, time.Second, time.Millisecond)
+	mu.Lock()
+	defer mu.Unlock()
+	require.Equal(t, []string{"billing", "analytics"}, order)
+}
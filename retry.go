@@ -0,0 +1,188 @@
+package cron
+
+import (
+	"context"
+	"errors"
+	"math"
+	"math/rand"
+	"time"
+)
+
+// RetryJitter is the strategy to randomize the delay between retries, so that
+// the failed jobs do not retry at the same moment.
+type RetryJitter int
+
+const (
+	// RetryJitterNone uses the delay as is.
+	RetryJitterNone RetryJitter = iota
+
+	// RetryJitterFull uses a random delay in [0, delay).
+	RetryJitterFull
+
+	// RetryJitterEqual uses a random delay in [delay/2, delay).
+	RetryJitterEqual
+)
+
+// RetryPolicy controls how WrapJobRetryPolicy retries a failed run.
+//
+// The delay before the n-th retry is InitialDelay * Multiplier^(n-1), capped by
+// MaxDelay and then randomized by Jitter.
+type RetryPolicy struct {
+	// MaxRetries is the max number of retries after the first attempt.
+	// The MaxRetries < 0 means no limited.
+	MaxRetries int64
+
+	// InitialDelay is the delay before the first retry, it must be greater than 0.
+	InitialDelay time.Duration
+
+	// Multiplier is the factor the delay grows by after each retry.
+	// The Multiplier < 1 is treated as 1, i.e. a fixed delay.
+	Multiplier float64
+
+	// MaxDelay caps the delay between retries. Zero means no limited.
+	MaxDelay time.Duration
+
+	// Jitter is the strategy to randomize the delay. The default is RetryJitterNone.
+	Jitter RetryJitter
+
+	// MaxElapsed is the max time from the start of the first attempt that a retry
+	// can start in. Zero means no limited.
+	MaxElapsed time.Duration
+
+	// IsRetryable reports whether the run failed with err should be retried.
+	// The nil means all errors are retryable. The error marked by Permanent is
+	// never retried.
+	IsRetryable func(err error) bool
+
+	// CancelAtNextFire cancels the context of the attempt when the next run of the
+	// job comes due, so that the run gives up at that moment.
+	//
+	// Regardless of it, a retry is never started at or after the next run.
+	CancelAtNextFire bool
+}
+
+// retryable reports whether the run failed with err should be retried.
+func (p *RetryPolicy) retryable(err error) bool {
+	var perm *permanentError
+	if errors.As(err, &perm) {
+		return false
+	}
+	return p.IsRetryable == nil || p.IsRetryable(err)
+}
+
+// delay returns the delay before the n-th retry, n starts from 1.
+func (p *RetryPolicy) delay(n int64) time.Duration {
+	d := float64(p.InitialDelay)
+	if p.Multiplier > 1 {
+		d *= math.Pow(p.Multiplier, float64(n-1))
+	}
+	if p.MaxDelay > 0 && d > float64(p.MaxDelay) {
+		d = float64(p.MaxDelay)
+	}
+	delay := time.Duration(math.MaxInt64)
+	if d < float64(math.MaxInt64) {
+		delay = time.Duration(d)
+	}
+
+	switch p.Jitter {
+	case RetryJitterFull:
+		delay = time.Duration(rand.Int63n(int64(delay)))
+	case RetryJitterEqual:
+		half := delay / 2
+		delay = half + time.Duration(rand.Int63n(int64(delay-half)))
+	}
+	return delay
+}
+
+// permanentError marks the error that should not be retried.
+type permanentError struct {
+	err error
+}
+
+func (e *permanentError) Error() string {
+	return e.err.Error()
+}
+
+func (e *permanentError) Unwrap() error {
+	return e.err
+}
+
+// Permanent wraps the err returned by a job to stop WrapJobRetryPolicy from
+// retrying the run. It returns nil if the err is nil.
+func Permanent(err error) error {
+	if err == nil {
+		return nil
+	}
+	return &permanentError{err: err}
+}
+
+// WrapJobRetryPolicy implements a JobWrapper to retry Run with the RetryPolicy.
+// It returns a *RetryError holding the errors of all attempts if the run failed.
+//
+// The retries stop when the ctxRetry or the context of job is done.
+func WrapJobRetryPolicy(ctxRetry context.Context, policy RetryPolicy) JobWrapper {
+	if policy.InitialDelay <= 0 {
+		panic("cron: WrapJobRetryPolicy: the initial delay must be greater than 0")
+	}
+
+	return func(job Job) Job {
+		return JobFunc(func(ctx context.Context) error {
+			// The next run is planned by the Clock of Crontab.
+			var next time.Time
+			now := time.Now
+			if rc := getRunContext(ctx); rc != nil {
+				next, now = rc.next, rc.clock.Now
+			}
+			start := now()
+
+			ctxRun := ctx
+			if policy.CancelAtNextFire && !next.IsZero() {
+				var cancel context.CancelFunc
+				ctxRun, cancel = context.WithTimeout(ctx, next.Sub(start))
+				defer cancel()
+			}
+
+			key, _ := JobKey(ctx)
+			logger := loggerFromContext(ctx)
+
+			var errs []error
+			for n := int64(1); ; n++ {
+				err := job.Run(ctxRun)
+				if err == nil {
+					return nil
+				}
+				if perm, ok := err.(*permanentError); ok {
+					errs = append(errs, perm.err)
+				} else {
+					errs = append(errs, err)
+				}
+
+				if !policy.retryable(err) || (policy.MaxRetries >= 0 && n > policy.MaxRetries) {
+					break
+				}
+				delay := policy.delay(n)
+				at := now().Add(delay)
+				if policy.MaxElapsed > 0 && at.Sub(start) > policy.MaxElapsed {
+					break
+				}
+				if !next.IsZero() && !at.Before(next) {
+					break
+				}
+
+				logger.Warn("job run failed, will retry", "key", key, "attempt", n, "delay", delay, "error", err)
+				timer := time.NewTimer(delay)
+				select {
+				case <-timer.C:
+					continue
+				case <-ctxRetry.Done():
+				case <-ctxRun.Done():
+				}
+				timer.Stop()
+				break
+			}
+
+			logger.Warn("job run failed, give up retry", "key", key, "attempts", len(errs), "error", errs[len(errs)-1])
+			return &RetryError{Errors: errs}
+		})
+	}
+}
@@ -0,0 +1,127 @@
+package cron
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestRetryPolicy_Delay(t *testing.T) {
+	policy := RetryPolicy{InitialDelay: time.Millisecond * 10, Multiplier: 2, MaxDelay: time.Millisecond * 50}
+	expected := []time.Duration{10, 20, 40, 50, 50}
+	for i, d := range expected {
+		require.Equal(t, d*time.Millisecond, policy.delay(int64(i+1)))
+	}
+
+	// The Multiplier < 1 means a fixed delay.
+	policy = RetryPolicy{InitialDelay: time.Millisecond * 10}
+	require.Equal(t, time.Millisecond*10, policy.delay(5))
+
+	// No overflow.
+	policy = RetryPolicy{InitialDelay: time.Hour, Multiplier: 10}
+	require.True(t, policy.delay(100) > 0)
+
+	policy = RetryPolicy{InitialDelay: time.Second, Multiplier: 2}
+	for i := 0; i < 100; i++ {
+		policy.Jitter = RetryJitterFull
+		d := policy.delay(2)
+		require.True(t, d >= 0 && d < time.Second*2)
+		policy.Jitter = RetryJitterEqual
+		d = policy.delay(2)
+		require.True(t, d >= time.Second && d < time.Second*2)
+	}
+}
+
+func TestWrapJobRetryPolicy(t *testing.T) {
+	errJob := errors.New("job failed")
+	errFatal := errors.New("job fatal")
+
+	run := func(policy RetryPolicy, fn func(n int) error) (int, error) {
+		var n int
+		err := WrapJobRetryPolicy(context.Background(), policy)(JobFunc(func(ctx context.Context) error {
+			n++
+			return fn(n)
+		})).Run(context.Background())
+		return n, err
+	}
+
+	policy := RetryPolicy{MaxRetries: 3, InitialDelay: time.Millisecond, Multiplier: 2}
+
+	// Succeeds after retries.
+	n, err := run(policy, func(n int) error {
+		if n < 3 {
+			return errJob
+		}
+		return nil
+	})
+	require.Nil(t, err)
+	require.Equal(t, 3, n)
+
+	// Fails after all retries.
+	n, err = run(policy, func(n int) error { return errJob })
+	require.Equal(t, 4, n)
+	var retryErr *RetryError
+	require.True(t, errors.As(err, &retryErr))
+	require.Equal(t, []error{errJob, errJob, errJob, errJob}, retryErr.Errors)
+	require.True(t, errors.Is(err, errJob))
+
+	// Stops at the permanent error.
+	n, err = run(policy, func(n int) error {
+		if n == 2 {
+			return Permanent(errFatal)
+		}
+		return errJob
+	})
+	require.Equal(t, 2, n)
+	require.True(t, errors.As(err, &retryErr))
+	require.Equal(t, []error{errJob, errFatal}, retryErr.Errors)
+	require.True(t, errors.Is(err, errJob))
+	require.True(t, errors.Is(err, errFatal))
+	require.Nil(t, Permanent(nil))
+
+	// Stops at the error not retryable.
+	policy.IsRetryable = func(err error) bool { return err != errFatal }
+	n, _ = run(policy, func(n int) error { return errFatal })
+	require.Equal(t, 1, n)
+
+	// Stops after the max elapsed.
+	policy = RetryPolicy{MaxRetries: -1, InitialDelay: time.Millisecond * 20, MaxElapsed: time.Millisecond * 50}
+	n, _ = run(policy, func(n int) error { return errJob })
+	require.Equal(t, 3, n)
+
+	require.Panics(t, func() { WrapJobRetryPolicy(context.Background(), RetryPolicy{}) })
+}
+
+func TestWrapJobRetryPolicy_NextFire(t *testing.T) {
+	errJob := errors.New("job failed")
+	newContext := func(next time.Time) context.Context {
+		rc := &runContext{key: "job1", next: next, clock: &systemClock{}, logger: DefaultLogger, emit: func(Event) {}}
+		return withRunContext(context.Background(), rc)
+	}
+
+	// No retry starts at or after the next fire.
+	var n int
+	job := WrapJobRetryPolicy(context.Background(), RetryPolicy{MaxRetries: -1, InitialDelay: time.Millisecond * 100})(JobFunc(func(ctx context.Context) error {
+		n++
+		return errJob
+	}))
+	require.NotNil(t, job.Run(newContext(time.Now().Add(time.Millisecond*150))))
+	require.Equal(t, 2, n)
+
+	// The attempt is canceled when the next fire comes due.
+	n = 0
+	policy := RetryPolicy{MaxRetries: -1, InitialDelay: time.Millisecond, CancelAtNextFire: true}
+	job = WrapJobRetryPolicy(context.Background(), policy)(JobFunc(func(ctx context.Context) error {
+		n++
+		<-ctx.Done()
+		return ctx.Err()
+	}))
+	start := time.Now()
+	err := job.Run(newContext(start.Add(time.Millisecond * 50)))
+	require.True(t, time.Since(start) < time.Millisecond*500)
+	require.True(t, errors.Is(err, context.DeadlineExceeded))
+	require.Equal(t, 1, n)
+}
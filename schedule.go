@@ -1,23 +1,102 @@
 package cron
 
 import (
+	"errors"
 	"fmt"
 	"sync"
-	"sync/atomic"
 	"time"
 
-	"github.com/yu31/timewheel-go"
-
 	"github.com/yu31/cron-go/pkg/expr"
 )
 
-type ScheduleFunc = timewheel.ScheduleFunc
+// ScheduleFunc is an adapter to allow the use of ordinary functions as Schedule.
+type ScheduleFunc func(prev time.Time) time.Time
+
+// Next implements Schedule.
+func (f ScheduleFunc) Next(prev time.Time) time.Time {
+	return f(prev)
+}
 
 // Schedule used in scheduler.
 type Schedule interface {
-	timewheel.Schedule
+	// Next returns the next activation time later than prev.
+	// Zero time means the schedule is exhausted.
+	Next(prev time.Time) time.Time
+}
+
+// Validator is implemented by the Schedule that can be checked before submitted.
+// All of the built-in Schedule implement it.
+type Validator interface {
+	// Validate returns an error if the Schedule is invalid.
+	Validate() error
+}
+
+// keyBinder is implemented by the Schedule that depends on the job key.
+// The Crontab binds the job key to the schedule before it submitted.
+type keyBinder interface {
+	bindKey(key string) error
+}
+
+// bindKey binds the job key to the schedule if it implements keyBinder.
+func bindKey(schedule Schedule, key string) error {
+	if b, ok := schedule.(keyBinder); ok {
+		return b.bindKey(key)
+	}
+	return nil
+}
+
+// firstActivator is implemented by the Schedule that can be activated at a time
+// not later than the submitted time, e.g. the Appoint in past.
+type firstActivator interface {
+	first(now time.Time) time.Time
+}
+
+// firstActivation returns the first activation time of the schedule submitted
+// at now, it is Next(now) unless the schedule implements firstActivator.
+func firstActivation(schedule Schedule, now time.Time) time.Time {
+	if f, ok := schedule.(firstActivator); ok {
+		return f.first(now)
+	}
+	return schedule.Next(now)
 }
 
+// intervalChecker is implemented by the Schedule that activates at a fixed
+// interval, which must not be shorter than the minimum of Driver.
+type intervalChecker interface {
+	checkInterval(min time.Duration) error
+}
+
+// checkInterval checks the interval of the schedule against the minimum of
+// Driver if it implements intervalChecker.
+func checkInterval(schedule Schedule, min time.Duration) error {
+	if c, ok := schedule.(intervalChecker); ok {
+		return c.checkInterval(min)
+	}
+	return nil
+}
+
+// validateSchedule checks the schedule with Validate if it implements Validator.
+func validateSchedule(schedule Schedule) error {
+	if schedule == nil {
+		return errors.New("cron: schedule cannot be nil")
+	}
+	if v, ok := schedule.(Validator); ok {
+		return v.Validate()
+	}
+	return nil
+}
+
+// validatePeriod checks the validity period of Begin and End.
+func validatePeriod(begin time.Time, end time.Time) error {
+	if !begin.IsZero() && !end.IsZero() && end.Before(begin) {
+		return fmt.Errorf("cron: end time %s is before begin time %s", end, begin)
+	}
+	return nil
+}
+
+// unixCronParser is the standard crontab parser with an optional year field.
+var unixCronParser = expr.New(expr.Minute | expr.Hour | expr.Dom | expr.Month | expr.Dow | expr.YearOptional | expr.Descriptor)
+
 // UnixCron represents a periodic task with standard unix crontab expression.
 type UnixCron struct {
 	// Begin is the start time of the validity period of the job.
@@ -29,22 +108,87 @@ type UnixCron struct {
 	End time.Time
 
 	// Express is the crontab express specification.
-	// Notice: It will panics if express is invalid.
+	// An optional sixth field specifies the years, e.g. "0 0 1 1 * 2027-2030".
+	// Notice: Next will panics if express is invalid, use Validate to check it.
 	Express string
 
+	// Parser is used to parse the Express, e.g. expr.WithSeconds.
+	// Zero means the standard crontab parser with an optional year field.
+	Parser expr.Parser
+
+	// HashKey is the key to resolve the hashed "H" tokens in Express, see
+	// expr.Parser.ParseWithKey. Empty means the job key, it is set by Crontab
+	// when submitted, so the UnixCron cannot be submitted with another key.
+	HashKey string
+
+	boundKey     string // the job key bound to HashKey by Crontab.
 	once         sync.Once
 	exprSchedule expr.Schedule // the exprSchedule of parse by crontab express.
+	parseErr     error         // the error of parse crontab express.
+}
+
+// ParseUnixCron creates a UnixCron with the crontab express. It returns an
+// error if the express is invalid. The hashed "H" tokens are resolved again
+// with the job key when submitted.
+func ParseUnixCron(express string) (*UnixCron, error) {
+	job := &UnixCron{Express: express}
+	if err := job.Validate(); err != nil {
+		return nil, err
+	}
+	return job, nil
 }
 
-// Next is called be timewheel.
+// Validate implements Validator. It parses the crontab express and checks
+// the validity period.
+func (job *UnixCron) Validate() error {
+	if err := job.parse(); err != nil {
+		return err
+	}
+	return validatePeriod(job.Begin, job.End)
+}
+
+// parse parses the crontab express only once.
+func (job *UnixCron) parse() error {
+	job.once.Do(job.parseWithKey)
+	return job.parseErr
+}
+
+// parseWithKey parses the crontab express with the HashKey.
+func (job *UnixCron) parseWithKey() {
+	var err error
+	parser := job.Parser
+	if parser == (expr.Parser{}) {
+		parser = unixCronParser
+	}
+	job.exprSchedule, err = parser.ParseWithKey(job.Express, job.HashKey)
+	if err != nil {
+		job.parseErr = fmt.Errorf("cron: parse express error:%v", err)
+	}
+}
+
+// bindKey implements keyBinder. The express already parsed with the empty
+// key, e.g. by ParseUnixCron, is parsed again with the key. It returns an
+// error if the HashKey has been bound to another key.
+func (job *UnixCron) bindKey(key string) error {
+	if job.boundKey != "" && job.boundKey != key {
+		return fmt.Errorf("cron: the UnixCron has been submitted with key %q", job.boundKey)
+	}
+	if job.HashKey != "" || key == "" {
+		return nil
+	}
+	job.HashKey = key
+	job.boundKey = key
+	if job.exprSchedule != nil {
+		job.parseWithKey()
+	}
+	return nil
+}
+
+// Next is called be Driver.
 func (job *UnixCron) Next(prev time.Time) time.Time {
-	job.once.Do(func() {
-		var err error
-		job.exprSchedule, err = expr.Standard.Parse(job.Express)
-		if err != nil {
-			panic(fmt.Errorf("cron: parse express error:%v", err))
-		}
-	})
+	if err := job.parse(); err != nil {
+		panic(err)
+	}
 
 	var next time.Time
 
@@ -73,11 +217,28 @@ type Interval struct {
 	End time.Time
 
 	// Interval is the time interval between each task.
-	// The value cannot less than 10ms.
+	// The value must be positive, and cannot less than 10ms with the default
+	// timewheel driver, see NewTimeWheelDriver.
 	Interval time.Duration
 }
 
-// Next is called be timewheel.
+// Validate implements Validator.
+func (job *Interval) Validate() error {
+	if job.Interval <= 0 {
+		return fmt.Errorf("cron: interval %s must be positive", job.Interval)
+	}
+	return validatePeriod(job.Begin, job.End)
+}
+
+// checkInterval implements intervalChecker.
+func (job *Interval) checkInterval(min time.Duration) error {
+	if job.Interval < min {
+		return fmt.Errorf("cron: interval %s is less than %s", job.Interval, min)
+	}
+	return nil
+}
+
+// Next is called be Driver.
 func (job *Interval) Next(prev time.Time) time.Time {
 	var next time.Time
 
@@ -96,18 +257,29 @@ func (job *Interval) Next(prev time.Time) time.Time {
 }
 
 // Appoint used to perform the task at a specified time.
+// The job submitted with an Appoint in past is run immediately.
 type Appoint struct {
 	// Time is the task execute time.
 	Time time.Time
+}
 
-	// done indicates whether the action has been performed.
-	done int32
+// Validate implements Validator.
+func (job *Appoint) Validate() error {
+	if job.Time.IsZero() {
+		return errors.New("cron: appoint time cannot be zero")
+	}
+	return nil
 }
 
-// Next is called be timewheel.
-func (job *Appoint) Next(time.Time) time.Time {
-	if atomic.CompareAndSwapInt32(&job.done, 0, 1) {
+// Next is called be Driver. It returns the Time if prev is before it.
+func (job *Appoint) Next(prev time.Time) time.Time {
+	if prev.Before(job.Time) {
 		return job.Time
 	}
 	return time.Time{}
 }
+
+// first implements firstActivator.
+func (job *Appoint) first(time.Time) time.Time {
+	return job.Time
+}
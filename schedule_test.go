@@ -1,12 +1,15 @@
 package cron
 
 import (
+	"context"
 	"fmt"
 	"sync"
 	"testing"
 	"time"
 
 	"github.com/stretchr/testify/require"
+
+	"github.com/yu31/cron-go/pkg/expr"
 )
 
 func TestSchedule_UnixCron1(t *testing.T) {
@@ -241,6 +244,107 @@ func TestSchedule_UnixCron3(t *testing.T) {
 	}
 }
 
+func TestSchedule_UnixCronYear(t *testing.T) {
+	sch := UnixCron{Express: "0 0 1 1 * 2027-2028"}
+
+	current, err := time.ParseInLocation("2006-01-02 15:04:05", "2022-01-18 3:00:00", time.Local)
+	require.Nil(t, err)
+
+	var nexts []string
+	for next := sch.Next(current); !next.IsZero(); next = sch.Next(next) {
+		nexts = append(nexts, next.Format("2006-01-02 15:04:05"))
+	}
+	require.Equal(t, []string{"2027-01-01 00:00:00", "2028-01-01 00:00:00"}, nexts)
+}
+
+func TestSchedule_UnixCronParser(t *testing.T) {
+	begin, err := time.ParseInLocation("2006-01-02 15:04:05", "2022-01-18 3:00:00", time.Local)
+	require.Nil(t, err)
+	end := begin.Add(time.Minute)
+	sch := UnixCron{Begin: begin, End: end, Express: "*/20 * * * * *", Parser: expr.WithSeconds}
+
+	var nexts []string
+	for next := sch.Next(begin.Add(-time.Hour)); !next.IsZero(); next = sch.Next(next) {
+		nexts = append(nexts, next.Format("15:04:05"))
+	}
+	require.Equal(t, []string{"03:00:20", "03:00:40", "03:01:00"}, nexts)
+
+	// The descriptors are not accepted by the parser.
+	parser := expr.New(expr.Minute | expr.Hour | expr.Dom | expr.Month | expr.Dow)
+	require.Panics(t, func() {
+		(&UnixCron{Express: "@daily", Parser: parser}).Next(begin)
+	})
+}
+
+func TestSchedule_Validate(t *testing.T) {
+	begin := time.Unix(662688000, 0)
+	end := time.Unix(2556144000, 0)
+	cases := []struct {
+		schedule Schedule
+		valid    bool
+	}{
+		{&UnixCron{Express: "*/5 * * * *"}, true},
+		{&UnixCron{Express: "*/5 * * * * 2027"}, true},
+		{&UnixCron{Express: "*/5 * * *"}, false},
+		{&UnixCron{Express: "61 * * * *"}, false},
+		{&UnixCron{Express: "*/5 * * * *", Begin: end, End: begin}, false},
+		{&UnixCron{Express: "*/5 * * * * *", Parser: expr.WithSeconds}, true},
+		{&Interval{Interval: time.Second}, true},
+		{&Interval{Interval: time.Millisecond}, true},
+		{&Interval{Interval: 0}, false},
+		{&Interval{Interval: time.Second, Begin: end, End: begin}, false},
+		{&Appoint{Time: end}, true},
+		{&Appoint{}, false},
+	}
+	for _, c := range cases {
+		err := c.schedule.(Validator).Validate()
+		require.Equal(t, c.valid, err == nil, "%#v: %v", c.schedule, err)
+	}
+
+	sch, err := ParseUnixCron("*/5 * * * *")
+	require.Nil(t, err)
+	require.Equal(t, "*/5 * * * *", sch.Express)
+
+	sch, err = ParseUnixCron("*/5 * *")
+	require.NotNil(t, err)
+	require.Nil(t, sch)
+}
+
+func TestSchedule_Between(t *testing.T) {
+	from, err := time.ParseInLocation("2006-01-02 15:04:05", "2022-01-18 3:00:00", time.Local)
+	require.Nil(t, err)
+	to := from.Add(time.Minute * 15)
+
+	require.Len(t, expr.Between(&UnixCron{Express: "*/5 * * * *"}, from, to), 3)
+	require.Len(t, expr.Between(&Interval{Interval: time.Minute, End: to.Add(-time.Minute)}, from, to), 14)
+	require.Equal(t, []time.Time{to}, expr.Between(&Appoint{Time: to}, from, to))
+	require.Len(t, expr.NextN(&UnixCron{Express: "0 0 1 1 * 2027-2028"}, from, 5), 2)
+}
+
+func TestSchedule_AppointPreview(t *testing.T) {
+	at := time.Now().Add(time.Millisecond * 20)
+	schedule := &Appoint{Time: at}
+
+	// The previews have no side effects.
+	require.Equal(t, []time.Time{at}, expr.NextN(schedule, at.Add(-time.Hour), 3))
+	require.Equal(t, []time.Time{at}, expr.Between(schedule, at.Add(-time.Hour), at))
+	require.Empty(t, expr.NextN(schedule, at, 3))
+
+	cron := New()
+	require.Nil(t, cron.Start())
+	defer cron.Stop()
+	runC := make(chan struct{}, 1)
+	require.Nil(t, cron.Submit(context.Background(), "job1", JobFunc(func(ctx context.Context) error {
+		runC <- struct{}{}
+		return nil
+	}), schedule))
+	select {
+	case <-runC:
+	case <-time.After(time.Second):
+		t.Fatal("the appoint is not run")
+	}
+}
+
 func TestSchedule_BeginGreaterThanEnd(t *testing.T) {
 	var err error
 	var begin time.Time
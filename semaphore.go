@@ -0,0 +1,135 @@
+package cron
+
+import (
+	"container/list"
+	"context"
+	"sync"
+	"time"
+)
+
+// Semaphore limits the number of jobs running at once. The jobs that cannot get
+// a slot wait in a FIFO queue.
+//
+// It is shared by the jobs decorated with WrapJobSemaphore, or the jobs in the
+// same group of Crontab, see WithConcurrencyGroup.
+type Semaphore struct {
+	limit   int
+	timeout time.Duration
+
+	// The fields below are protected by mu.
+	mu      *sync.Mutex
+	running int
+	waiters *list.List // the channels closed when the slot handed over.
+}
+
+// NewSemaphore creates a Semaphore that allows at most limit jobs running at once.
+//
+// The timeout is the max time a job waits in the queue for a slot. The timeout == 0
+// means waiting until the context of job is done, and the timeout < 0 means no
+// queueing, the job is skipped immediately if no slot is available.
+func NewSemaphore(limit int, timeout time.Duration) *Semaphore {
+	if limit <= 0 {
+		panic("cron: NewSemaphore: the limit must be greater than 0")
+	}
+	return &Semaphore{
+		limit:   limit,
+		timeout: timeout,
+		mu:      new(sync.Mutex),
+		running: 0,
+		waiters: list.New(),
+	}
+}
+
+// Running returns the number of jobs holding a slot.
+func (s *Semaphore) Running() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.running
+}
+
+// Waiting returns the number of jobs waiting in the queue.
+func (s *Semaphore) Waiting() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.waiters.Len()
+}
+
+// acquire gets a slot, waits in the queue if no slot is available. The queued
+// reports whether it has waited in the queue. The ok is false if the timeout
+// expired or the ctx is done before getting a slot.
+func (s *Semaphore) acquire(ctx context.Context) (queued bool, ok bool) {
+	s.mu.Lock()
+	if s.running < s.limit && s.waiters.Len() == 0 {
+		s.running++
+		s.mu.Unlock()
+		return false, true
+	}
+	if s.timeout < 0 {
+		s.mu.Unlock()
+		return false, false
+	}
+	ready := make(chan struct{})
+	elem := s.waiters.PushBack(ready)
+	s.mu.Unlock()
+
+	var timeoutC <-chan time.Time
+	if s.timeout > 0 {
+		timer := time.NewTimer(s.timeout)
+		defer timer.Stop()
+		timeoutC = timer.C
+	}
+
+	select {
+	case <-ready:
+		return true, true
+	case <-ctx.Done():
+	case <-timeoutC:
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	select {
+	case <-ready:
+		// The slot handed over while giving up.
+		return true, true
+	default:
+		s.waiters.Remove(elem)
+		return true, false
+	}
+}
+
+// release returns the slot, which is handed over to the first waiter if any.
+func (s *Semaphore) release() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if front := s.waiters.Front(); front != nil {
+		s.waiters.Remove(front)
+		close(front.Value.(chan struct{}))
+		return
+	}
+	s.running--
+}
+
+// WrapJobSemaphore implements a JobWrapper to run the job only if a slot of the
+// Semaphore is acquired. The run waited for a slot is reported as EventRunDelayed,
+// and the run that cannot get a slot is skipped and reported as EventRunSkipped.
+func WrapJobSemaphore(sem *Semaphore) JobWrapper {
+	return func(job Job) Job {
+		return JobFunc(func(ctx context.Context) error {
+			start := time.Now()
+			queued, ok := sem.acquire(ctx)
+			if !ok {
+				key, _ := JobKey(ctx)
+				loggerFromContext(ctx).Info("job run skipped, no slot of semaphore is available", "key", key)
+				markSkipped(ctx)
+				return nil
+			}
+			defer sem.release()
+
+			if queued {
+				markDelayed(ctx, time.Since(start))
+			}
+			return job.Run(ctx)
+		})
+	}
+}
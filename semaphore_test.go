@@ -0,0 +1,183 @@
+package cron
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestSemaphore(t *testing.T) {
+	sem := NewSemaphore(2, 0)
+	ctx := context.Background()
+
+	for i := 0; i < 2; i++ {
+		queued, ok := sem.acquire(ctx)
+		require.False(t, queued)
+		require.True(t, ok)
+	}
+	require.Equal(t, 2, sem.Running())
+
+	// The waiters get the slot in FIFO order.
+	var mu sync.Mutex
+	var order []int
+	var wg sync.WaitGroup
+	for i := 0; i < 3; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			queued, ok := sem.acquire(ctx)
+			require.True(t, queued)
+			require.True(t, ok)
+			mu.Lock()
+			order = append(order, i)
+			mu.Unlock()
+		}(i)
+		require.Eventually(t, func() bool { return sem.Waiting() == i+1 }, time.Second, time.Millisecond)
+	}
+
+	for i := 0; i < 3; i++ {
+		sem.release()
+		require.Eventually(t, func() bool {
+			mu.Lock()
+			defer mu.Unlock()
+			return len(order) == i+1
+		}, time.Second, time.Millisecond)
+	}
+	wg.Wait()
+	require.Equal(t, []int{0, 1, 2}, order)
+	require.Equal(t, 2, sem.Running())
+	require.Equal(t, 0, sem.Waiting())
+
+	// Gives up when the context is done.
+	ctxCancel, cancel := context.WithTimeout(ctx, time.Millisecond*20)
+	defer cancel()
+	queued, ok := sem.acquire(ctxCancel)
+	require.True(t, queued)
+	require.False(t, ok)
+	require.Equal(t, 0, sem.Waiting())
+
+	sem.release()
+	sem.release()
+	require.Equal(t, 0, sem.Running())
+
+	require.Panics(t, func() { NewSemaphore(0, 0) })
+}
+
+func TestSemaphore_Timeout(t *testing.T) {
+	ctx := context.Background()
+
+	// No queueing.
+	sem := NewSemaphore(1, -1)
+	_, ok := sem.acquire(ctx)
+	require.True(t, ok)
+	queued, ok := sem.acquire(ctx)
+	require.False(t, queued)
+	require.False(t, ok)
+
+	// Gives up after timeout.
+	sem = NewSemaphore(1, time.Millisecond*20)
+	_, ok = sem.acquire(ctx)
+	require.True(t, ok)
+	start := time.Now()
+	queued, ok = sem.acquire(ctx)
+	require.True(t, queued)
+	require.False(t, ok)
+	require.True(t, time.Since(start) >= time.Millisecond*20)
+	require.Equal(t, 0, sem.Waiting())
+}
+
+func TestCrontab_ConcurrencyGroup(t *testing.T) {
+	var mu sync.Mutex
+	var events []Event
+	listener := ListenerFunc(func(event Event) {
+		if event.Type == EventRunDelayed || event.Type == EventRunSkipped {
+			mu.Lock()
+			events = append(events, event)
+			mu.Unlock()
+		}
+	})
+	cron := New(
+		WithListener(listener),
+		WithConcurrencyGroup("db", 1, 0),
+		WithConcurrencyGroup("nowait", 1, -1),
+	)
+	require.Nil(t, cron.Start())
+	defer cron.Stop()
+
+	job := JobFunc(func(ctx context.Context) error { return nil })
+	require.NotNil(t, cron.Submit(context.Background(), "job1", job, &Interval{Interval: time.Hour}, WithGroup("unknown")))
+	require.False(t, cron.Has("job1"))
+
+	startC := make(chan struct{})
+	doneC := make(chan struct{})
+	blocking := JobFunc(func(ctx context.Context) error {
+		close(startC)
+		<-doneC
+		return nil
+	})
+	require.Nil(t, cron.Submit(context.Background(), "job1", blocking, &Interval{Interval: time.Hour}, WithGroup("db")))
+	require.Nil(t, cron.Submit(context.Background(), "job2", job, &Interval{Interval: time.Hour}, WithGroup("db")))
+	require.Nil(t, cron.Submit(context.Background(), "job3", job, &Interval{Interval: time.Hour}, WithGroup("nowait")))
+
+	go func() { _ = cron.RunNow(context.Background(), "job1") }()
+	<-startC
+
+	// The job2 waits for the slot held by job1.
+	errC := make(chan error)
+	go func() { errC <- cron.RunNow(context.Background(), "job2") }()
+	time.Sleep(time.Millisecond * 30)
+	close(doneC)
+	require.Nil(t, <-errC)
+
+	// The group of job3 has a free slot.
+	require.Nil(t, cron.RunNow(context.Background(), "job3"))
+
+	mu.Lock()
+	defer mu.Unlock()
+	require.Len(t, events, 1)
+	require.Equal(t, EventRunDelayed, events[0].Type)
+	require.Equal(t, "job2", events[0].Key)
+	require.True(t, events[0].Duration >= time.Millisecond*30)
+
+	info, _ := cron.Get("job2")
+	require.Equal(t, int64(1), info.Runs)
+	require.Nil(t, info.LastError)
+}
+
+func TestCrontab_ConcurrencyGroupSkipped(t *testing.T) {
+	var mu sync.Mutex
+	var skipped []string
+	listener := ListenerFunc(func(event Event) {
+		if event.Type == EventRunSkipped {
+			mu.Lock()
+			skipped = append(skipped, event.Key)
+			mu.Unlock()
+		}
+	})
+	cron := New(WithListener(listener), WithConcurrencyGroup("db", 1, -1))
+	require.Nil(t, cron.Start())
+	defer cron.Stop()
+
+	startC := make(chan struct{})
+	doneC := make(chan struct{})
+	defer close(doneC)
+	blocking := JobFunc(func(ctx context.Context) error {
+		close(startC)
+		<-doneC
+		return nil
+	})
+	job := JobFunc(func(ctx context.Context) error { return nil })
+	require.Nil(t, cron.Submit(context.Background(), "job1", blocking, &Interval{Interval: time.Hour}, WithGroup("db")))
+	require.Nil(t, cron.Submit(context.Background(), "job2", job, &Interval{Interval: time.Hour}, WithGroup("db")))
+
+	go func() { _ = cron.RunNow(context.Background(), "job1") }()
+	<-startC
+	require.Equal(t, ErrJobSkipped, cron.RunNow(context.Background(), "job2"))
+
+	mu.Lock()
+	defer mu.Unlock()
+	require.Equal(t, []string{"job2"}, skipped)
+}
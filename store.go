@@ -0,0 +1,152 @@
+package cron
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/yu31/cron-go/pkg/expr"
+)
+
+// The type of ScheduleSpec.
+const (
+	ScheduleTypeUnixCron = "unix_cron"
+	ScheduleTypeInterval = "interval"
+	ScheduleTypeAppoint  = "appoint"
+)
+
+// ScheduleSpec is a serializable description of the built-in Schedule.
+type ScheduleSpec struct {
+	// Type is the type of schedule, one of ScheduleTypeUnixCron, ScheduleTypeInterval, ScheduleTypeAppoint.
+	Type string `json:"type"`
+
+	// Begin and End are the validity period of UnixCron and Interval.
+	Begin time.Time `json:"begin"`
+	End   time.Time `json:"end"`
+
+	// Express is the crontab express of UnixCron.
+	Express string `json:"express,omitempty"`
+
+	// Parser is the options of the Parser of UnixCron. Zero means the default parser.
+	Parser expr.Option `json:"parser,omitempty"`
+
+	// HashKey is the key to resolve the hashed tokens of UnixCron.
+	HashKey string `json:"hash_key,omitempty"`
+
+	// Interval is the time interval of Interval.
+	Interval time.Duration `json:"interval,omitempty"`
+
+	// Time is the execute time of Appoint.
+	Time time.Time `json:"time"`
+}
+
+// NewScheduleSpec returns the ScheduleSpec of schedule.
+// Only the built-in UnixCron, Interval and Appoint are supported.
+func NewScheduleSpec(schedule Schedule) (ScheduleSpec, error) {
+	switch s := schedule.(type) {
+	case *UnixCron:
+		return ScheduleSpec{Type: ScheduleTypeUnixCron, Begin: s.Begin, End: s.End, Express: s.Express, Parser: s.Parser.Options(), HashKey: s.HashKey}, nil
+	case *Interval:
+		return ScheduleSpec{Type: ScheduleTypeInterval, Begin: s.Begin, End: s.End, Interval: s.Interval}, nil
+	case *Appoint:
+		return ScheduleSpec{Type: ScheduleTypeAppoint, Time: s.Time}, nil
+	}
+	return ScheduleSpec{}, fmt.Errorf("cron: unsupported schedule type %T", schedule)
+}
+
+// Schedule creates the Schedule described by spec.
+func (spec ScheduleSpec) Schedule() (Schedule, error) {
+	switch spec.Type {
+	case ScheduleTypeUnixCron:
+		schedule := &UnixCron{Begin: spec.Begin, End: spec.End, Express: spec.Express, HashKey: spec.HashKey}
+		if spec.Parser != 0 {
+			schedule.Parser = expr.New(spec.Parser)
+		}
+		return schedule, nil
+	case ScheduleTypeInterval:
+		return &Interval{Begin: spec.Begin, End: spec.End, Interval: spec.Interval}, nil
+	case ScheduleTypeAppoint:
+		return &Appoint{Time: spec.Time}, nil
+	}
+	return nil, fmt.Errorf("cron: unsupported schedule type %q", spec.Type)
+}
+
+// Record represents a job saved in the Store.
+type Record struct {
+	// Key is the unique key of the job.
+	Key string `json:"key"`
+
+	// Schedule is the description of the Schedule of job.
+	Schedule ScheduleSpec `json:"schedule"`
+
+	// JobType is the name to find the JobFactory in Registry.
+	JobType string `json:"job_type"`
+
+	// Payload is the argument passed to the JobFactory.
+	Payload []byte `json:"payload"`
+
+	// Misfire and Backlog are the MisfirePolicy to handle the runs missed
+	// since LastRun when the job reloaded, or since NextRun if LastRun is zero.
+	Misfire MisfirePolicy `json:"misfire"`
+	Backlog int           `json:"backlog"`
+
+	// Group is the name of concurrency group of the job.
+	Group string `json:"group,omitempty"`
+
+	// Priority is the priority of the job in the worker pool.
+	Priority Priority `json:"priority,omitempty"`
+
+	// LastRun is the planned time of the latest completed run.
+	LastRun time.Time `json:"last_run"`
+
+	// NextRun is the planned time of the next run. It is the first planned time
+	// until the first run completed.
+	NextRun time.Time `json:"next_run"`
+}
+
+// Store used to save the jobs so that they survive restarts.
+type Store interface {
+	// Save adds or updates the record with its key.
+	Save(record *Record) error
+
+	// Delete deletes the record with specified key. It returns nil if the record not exists.
+	Delete(key string) error
+
+	// Load returns all records in the store.
+	Load() ([]*Record, error)
+}
+
+// JobFactory creates a Job with the payload of Record.
+type JobFactory func(payload []byte) (Job, error)
+
+// Registry maps the job type names to JobFactory.
+type Registry struct {
+	mu        *sync.RWMutex
+	factories map[string]JobFactory
+}
+
+// NewRegistry creates a Registry.
+func NewRegistry() *Registry {
+	return &Registry{
+		mu:        new(sync.RWMutex),
+		factories: make(map[string]JobFactory),
+	}
+}
+
+// Register adds or updates the JobFactory with the job type name.
+func (r *Registry) Register(jobType string, factory JobFactory) {
+	r.mu.Lock()
+	r.factories[jobType] = factory
+	r.mu.Unlock()
+}
+
+// New creates a Job with the JobFactory of jobType.
+func (r *Registry) New(jobType string, payload []byte) (Job, error) {
+	r.mu.RLock()
+	factory, ok := r.factories[jobType]
+	r.mu.RUnlock()
+	if !ok {
+		return nil, fmt.Errorf("cron: unregistered job type %q", jobType)
+	}
+	return factory(payload)
+}
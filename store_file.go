@@ -0,0 +1,136 @@
+package cron
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+)
+
+var (
+	_ Store = (*FileStore)(nil)
+)
+
+// FileStore is a Store that saves all records in a JSON file.
+//
+// The file is rewritten atomically on each change by writing a temporary
+// file in the same directory and renaming it. The FileStore does not support
+// to be shared by multiple processes.
+type FileStore struct {
+	mu   *sync.Mutex
+	path string
+}
+
+// NewFileStore creates a FileStore with the file path.
+// The file will be created on the first Save if it does not exist.
+func NewFileStore(path string) *FileStore {
+	return &FileStore{
+		mu:   new(sync.Mutex),
+		path: path,
+	}
+}
+
+func (s *FileStore) Save(record *Record) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	records, err := s.read()
+	if err != nil {
+		return err
+	}
+	records[record.Key] = record
+	return s.write(records)
+}
+
+func (s *FileStore) Delete(key string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	records, err := s.read()
+	if err != nil {
+		return err
+	}
+	if _, ok := records[key]; !ok {
+		return nil
+	}
+	delete(records, key)
+	return s.write(records)
+}
+
+func (s *FileStore) Load() ([]*Record, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	records, err := s.read()
+	if err != nil {
+		return nil, err
+	}
+	return sortRecords(records), nil
+}
+
+// read returns all records in the file. The file not exists means no records.
+func (s *FileStore) read() (map[string]*Record, error) {
+	records := make(map[string]*Record)
+	data, err := ioutil.ReadFile(s.path)
+	if os.IsNotExist(err) {
+		return records, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("cron: read store file: %w", err)
+	}
+	var list []*Record
+	if err = json.Unmarshal(data, &list); err != nil {
+		return nil, fmt.Errorf("cron: decode store file: %w", err)
+	}
+	for _, record := range list {
+		records[record.Key] = record
+	}
+	return records, nil
+}
+
+// write replaces the file with records atomically.
+func (s *FileStore) write(records map[string]*Record) (err error) {
+	data, err := json.MarshalIndent(sortRecords(records), "", "  ")
+	if err != nil {
+		return fmt.Errorf("cron: encode store file: %w", err)
+	}
+
+	dir, base := filepath.Split(s.path)
+	if dir == "" {
+		dir = "."
+	}
+	tmp, err := ioutil.TempFile(dir, base+".tmp*")
+	if err != nil {
+		return fmt.Errorf("cron: create store file: %w", err)
+	}
+	defer func() {
+		if err != nil {
+			_ = tmp.Close()
+			_ = os.Remove(tmp.Name())
+		}
+	}()
+
+	if _, err = tmp.Write(data); err != nil {
+		return fmt.Errorf("cron: write store file: %w", err)
+	}
+	if err = tmp.Sync(); err != nil {
+		return fmt.Errorf("cron: sync store file: %w", err)
+	}
+	if err = tmp.Close(); err != nil {
+		return fmt.Errorf("cron: close store file: %w", err)
+	}
+	if err = os.Rename(tmp.Name(), s.path); err != nil {
+		return fmt.Errorf("cron: rename store file: %w", err)
+	}
+	return nil
+}
+
+// sortRecords returns the records sorted by key.
+func sortRecords(records map[string]*Record) []*Record {
+	list := make([]*Record, 0, len(records))
+	for _, record := range records {
+		list = append(list, record)
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].Key < list[j].Key })
+	return list
+}
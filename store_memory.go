@@ -0,0 +1,50 @@
+package cron
+
+import (
+	"sort"
+	"sync"
+)
+
+var (
+	_ Store = (*MemoryStore)(nil)
+)
+
+// MemoryStore is an in-memory Store, it is usually used in tests.
+type MemoryStore struct {
+	mu      *sync.Mutex
+	records map[string]Record
+}
+
+// NewMemoryStore creates a MemoryStore.
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{
+		mu:      new(sync.Mutex),
+		records: make(map[string]Record),
+	}
+}
+
+func (s *MemoryStore) Save(record *Record) error {
+	s.mu.Lock()
+	s.records[record.Key] = *record
+	s.mu.Unlock()
+	return nil
+}
+
+func (s *MemoryStore) Delete(key string) error {
+	s.mu.Lock()
+	delete(s.records, key)
+	s.mu.Unlock()
+	return nil
+}
+
+func (s *MemoryStore) Load() ([]*Record, error) {
+	s.mu.Lock()
+	records := make([]*Record, 0, len(s.records))
+	for _, record := range s.records {
+		record := record
+		records = append(records, &record)
+	}
+	s.mu.Unlock()
+	sort.Slice(records, func(i, j int) bool { return records[i].Key < records[j].Key })
+	return records, nil
+}
@@ -0,0 +1,164 @@
+package cron
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/yu31/cron-go/pkg/expr"
+)
+
+func TestScheduleSpec(t *testing.T) {
+	begin := time.Unix(662688000, 0)
+	end := time.Unix(2556144000, 0)
+	schedules := []Schedule{
+		&UnixCron{Begin: begin, End: end, Express: "*/5 * * * *"},
+		&UnixCron{Begin: begin, End: end, Express: "*/5 * * * * *", Parser: expr.WithSeconds},
+		&Interval{Begin: begin, End: end, Interval: time.Minute},
+		&Appoint{Time: end},
+	}
+	for _, schedule := range schedules {
+		spec, err := NewScheduleSpec(schedule)
+		require.Nil(t, err)
+		got, err := spec.Schedule()
+		require.Nil(t, err)
+		require.Equal(t, schedule, got)
+	}
+
+	_, err := NewScheduleSpec(ScheduleFunc(func(t time.Time) time.Time { return t }))
+	require.NotNil(t, err)
+	_, err = ScheduleSpec{Type: "unknown"}.Schedule()
+	require.NotNil(t, err)
+}
+
+func TestFileStore(t *testing.T) {
+	dir, err := ioutil.TempDir("", "cron-store")
+	require.Nil(t, err)
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "jobs.json")
+	store := NewFileStore(path)
+
+	records, err := store.Load()
+	require.Nil(t, err)
+	require.Len(t, records, 0)
+
+	record1 := &Record{
+		Key:      "job1",
+		Schedule: ScheduleSpec{Type: ScheduleTypeInterval, Interval: time.Minute},
+		JobType:  "print",
+		Payload:  []byte("hello"),
+		Misfire:  MisfireRunAll,
+		LastRun:  time.Unix(662688000, 0).UTC(),
+	}
+	record2 := &Record{Key: "job2", Schedule: ScheduleSpec{Type: ScheduleTypeUnixCron, Express: "* * * * *"}}
+	require.Nil(t, store.Save(record2))
+	require.Nil(t, store.Save(record1))
+
+	records, err = NewFileStore(path).Load()
+	require.Nil(t, err)
+	require.Len(t, records, 2)
+	require.Equal(t, record1, records[0])
+	require.Equal(t, record2, records[1])
+
+	require.Nil(t, store.Delete("job2"))
+	require.Nil(t, store.Delete("job3"))
+	records, err = store.Load()
+	require.Nil(t, err)
+	require.Len(t, records, 1)
+
+	// No temporary file is left.
+	files, err := ioutil.ReadDir(dir)
+	require.Nil(t, err)
+	require.Len(t, files, 1)
+}
+
+func TestCrontab_SubmitPersistent(t *testing.T) {
+	store := NewMemoryStore()
+	registry := NewRegistry()
+	runC := make(chan string, 16)
+	registry.Register("print", func(payload []byte) (Job, error) {
+		return JobFunc(func(ctx context.Context) error {
+			runC <- string(payload)
+			return nil
+		}), nil
+	})
+
+	cron := New(WithStore(store, registry))
+	require.NotNil(t, cron.SubmitPersistent(context.Background(), "job1", "unknown", nil, &Interval{Interval: time.Hour}))
+	require.NotNil(t, cron.SubmitPersistent(context.Background(), "job1", "print", nil, ScheduleFunc(func(t time.Time) time.Time { return t })))
+
+	lastRun := time.Now().Add(-time.Hour*2 - time.Minute)
+	err := cron.SubmitPersistent(context.Background(), "job1", "print", []byte("hello"),
+		&Interval{Interval: time.Hour}, WithMisfire(MisfireRunOnce, lastRun))
+	require.Nil(t, err)
+	require.Nil(t, cron.Start())
+	require.Equal(t, "hello", <-runC)
+
+	require.Eventually(t, func() bool {
+		records, _ := store.Load()
+		return len(records) == 1 && records[0].LastRun.Equal(lastRun.Add(time.Hour*2))
+	}, time.Second, time.Millisecond*10)
+	cron.Stop()
+
+	// Reload the job from store on Start, the missed runs have been caught up.
+	cron = New(WithStore(store, registry))
+	require.Nil(t, cron.Start())
+	require.True(t, cron.Has("job1"))
+	time.Sleep(time.Millisecond * 50)
+	require.Len(t, runC, 0)
+
+	cron.Remove("job1")
+	records, err := store.Load()
+	require.Nil(t, err)
+	require.Len(t, records, 0)
+	cron.Stop()
+}
+
+func TestCrontab_SubmitPersistentAppoint(t *testing.T) {
+	store := NewMemoryStore()
+	registry := NewRegistry()
+	runC := make(chan string, 16)
+	registry.Register("print", func(payload []byte) (Job, error) {
+		return JobFunc(func(ctx context.Context) error {
+			runC <- string(payload)
+			return nil
+		}), nil
+	})
+
+	// The record of appoint is deleted after run.
+	cron := New(WithStore(store, registry))
+	require.Nil(t, cron.Start())
+	at := time.Now().Add(time.Millisecond * 20)
+	require.Nil(t, cron.SubmitPersistent(context.Background(), "job1", "print", []byte("hello"), &Appoint{Time: at}))
+	require.Equal(t, "hello", <-runC)
+	require.Eventually(t, func() bool {
+		records, _ := store.Load()
+		return len(records) == 0
+	}, time.Second, time.Millisecond*10)
+	cron.Stop()
+
+	// The appoint has run before restart is not run again.
+	require.Nil(t, store.Save(&Record{
+		Key:      "job2",
+		Schedule: ScheduleSpec{Type: ScheduleTypeAppoint, Time: at},
+		JobType:  "print",
+		Payload:  []byte("world"),
+		Misfire:  MisfireRunAll,
+		LastRun:  at,
+	}))
+	cron = New(WithStore(store, registry))
+	require.Nil(t, cron.Start())
+	defer cron.Stop()
+	require.False(t, cron.Has("job2"))
+	records, err := store.Load()
+	require.Nil(t, err)
+	require.Len(t, records, 0)
+	time.Sleep(time.Millisecond * 50)
+	require.Len(t, runC, 0)
+}
@@ -0,0 +1,67 @@
+package cron
+
+import (
+	"sync"
+	"time"
+)
+
+// scheduleTimer drives a Schedule with the single calls made by atFunc, it is the
+// Timer returned by the built-in Driver. The fn is called with the planned
+// activation time and the next activation time each time the schedule is expired.
+type scheduleTimer struct {
+	mu       *sync.Mutex
+	atFunc   func(t time.Time, fn func()) Timer
+	schedule Schedule
+	fn       func(planned time.Time, next time.Time)
+	current  Timer
+	closed   bool
+}
+
+// newScheduleTimer creates a scheduleTimer with the first activation time next.
+func newScheduleTimer(atFunc func(t time.Time, fn func()) Timer, schedule Schedule, next time.Time, fn func(planned time.Time, next time.Time)) *scheduleTimer {
+	t := &scheduleTimer{
+		mu:       new(sync.Mutex),
+		atFunc:   atFunc,
+		schedule: schedule,
+		fn:       fn,
+		current:  nil,
+		closed:   false,
+	}
+	t.mu.Lock()
+	t.submit(next)
+	t.mu.Unlock()
+	return t
+}
+
+// submit adds the activation of next by atFunc. The zero time means
+// the schedule is exhausted. It must be called with t.mu held.
+func (t *scheduleTimer) submit(next time.Time) {
+	if next.IsZero() {
+		t.current = nil
+		return
+	}
+	t.current = t.atFunc(next, func() {
+		t.mu.Lock()
+		if t.closed {
+			t.mu.Unlock()
+			return
+		}
+		// Submit the next activation before running, same as timewheel.ScheduleJob.
+		following := t.schedule.Next(next)
+		t.submit(following)
+		t.mu.Unlock()
+
+		t.fn(next, following)
+	})
+}
+
+// Close implements Timer. It does not wait for the running fn.
+func (t *scheduleTimer) Close() {
+	t.mu.Lock()
+	t.closed = true
+	if t.current != nil {
+		t.current.Close()
+		t.current = nil
+	}
+	t.mu.Unlock()
+}
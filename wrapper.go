@@ -2,7 +2,6 @@ package cron
 
 import (
 	"context"
-	"fmt"
 	"runtime"
 	"sync"
 	"sync/atomic"
@@ -41,24 +40,23 @@ func WrapJobWaitGroup(wg *sync.WaitGroup) JobWrapper {
 }
 
 // WrapJobRecover implements a JobWrapper to recover when Job run panics.
+// The panic is returned as a *PanicError, and logged with the Logger of Crontab.
 func WrapJobRecover() JobWrapper {
 	return func(job Job) Job {
 		return JobFunc(func(ctx context.Context) (err error) {
 			defer func() {
 				if r := recover(); r != nil {
-					var ok bool
-					err, ok = r.(error)
-					if !ok {
-						err = fmt.Errorf("%v", r)
-					}
-					err = fmt.Errorf("cron: job run panic with error: %v", err)
-
+					// Only the stack of the panicking goroutine.
 					const size = 64 << 10
 					buf := make([]byte, size)
-					i := runtime.Stack(buf, true)
+					i := runtime.Stack(buf, false)
 					buf = buf[:i]
-					// FIXME: use logger.
-					println(fmt.Sprintf("%v\n%s", err, string(buf)))
+					panicErr := &PanicError{Value: r, Stack: buf}
+					err = panicErr
+
+					key, _ := JobKey(ctx)
+					loggerFromContext(ctx).Error("job run panic", "key", key, "error", err, "stack", string(buf))
+					markPanicked(ctx, panicErr)
 				}
 			}()
 			err = job.Run(ctx)
@@ -70,6 +68,7 @@ func WrapJobRecover() JobWrapper {
 // WrapJobRetry implements a JobWrapper to retry Run when any error.
 // The limit < 0 means no limited.
 // The interval not allowed must be greater than 0.
+// See WrapJobRetryPolicy for retrying with backoff.
 func WrapJobRetry(ctxRetry context.Context, limit int64, interval time.Duration) JobWrapper {
 	if limit != 0 && interval <= 0 {
 		panic("cron: WrapJobRetry: the interval must be greater than 0")
@@ -84,10 +83,14 @@ func WrapJobRetry(ctxRetry context.Context, limit int64, interval time.Duration)
 				return
 			}
 
+			key, _ := JobKey(ctx)
+			logger := loggerFromContext(ctx)
+
 			ticker := time.NewTicker(interval)
 			i := int64(0)
 		LOOP:
 			for {
+				logger.Warn("job run failed, will retry", "key", key, "attempt", i+1, "error", err)
 				select {
 				case <-ticker.C:
 					if err = job.Run(ctx); err == nil {
@@ -122,6 +125,9 @@ func WrapJobSkipIfRunning() JobWrapper {
 
 		return JobFunc(func(ctx context.Context) error {
 			if !atomic.CompareAndSwapInt32(&running, 0, 1) {
+				key, _ := JobKey(ctx)
+				loggerFromContext(ctx).Info("job run skipped, the previous run is still running", "key", key)
+				markSkipped(ctx)
 				return nil
 			}
 			err := job.Run(ctx)
@@ -145,3 +151,86 @@ func WrapJobBlockIfRunning() JobWrapper {
 		})
 	}
 }
+
+// WrapJobTimeout implements a JobWrapper to cancel the context of job after the
+// timeout. It returns a *TimeoutError that matches ErrJobTimeout and wraps the
+// error of job if the job fails after the timeout expired.
+//
+// The grace <= 0 means waiting for the job to return. Otherwise, the job is
+// abandoned if it does not return within the grace after the timeout, and its
+// goroutine is left running; a job that ignores the context will not leak the
+// caller, e.g. the later runs blocked by WrapJobBlockIfRunning.
+//
+// Put it after WrapJobRetry in the JobChain so that the timeout applies per attempt:
+//     WithJobWrapper(WrapJobRetry(ctx, 3, time.Second), WrapJobTimeout(time.Minute, 0))
+func WrapJobTimeout(timeout time.Duration, grace time.Duration) JobWrapper {
+	if timeout <= 0 {
+		panic("cron: WrapJobTimeout: the timeout must be greater than 0")
+	}
+
+	return func(job Job) Job {
+		return JobFunc(func(ctx context.Context) error {
+			ctxTimeout, cancel := context.WithTimeout(ctx, timeout)
+			defer cancel()
+
+			// timedOut reports whether the run is canceled by the timeout
+			// instead of the parent context.
+			timedOut := func() bool {
+				return ctxTimeout.Err() == context.DeadlineExceeded && ctx.Err() == nil
+			}
+			result := func(err error) error {
+				if err != nil && timedOut() {
+					key, _ := JobKey(ctx)
+					loggerFromContext(ctx).Warn("job run timeout", "key", key, "timeout", timeout, "error", err)
+					return &TimeoutError{Timeout: timeout, Err: err}
+				}
+				return err
+			}
+
+			if grace <= 0 {
+				return result(job.Run(ctxTimeout))
+			}
+
+			// Run in a new goroutine so that it can be abandoned. The panic is
+			// passed back to be recovered by the JobWrapper before this one.
+			type outcome struct {
+				err      error
+				panicked bool
+				value    interface{}
+			}
+			doneC := make(chan outcome, 1)
+			go func() {
+				var o outcome
+				defer func() {
+					if r := recover(); r != nil {
+						o.panicked, o.value = true, r
+					}
+					doneC <- o
+				}()
+				o.err = job.Run(ctxTimeout)
+			}()
+
+			var o outcome
+			select {
+			case o = <-doneC:
+			case <-ctxTimeout.Done():
+				timer := time.NewTimer(grace)
+				defer timer.Stop()
+				select {
+				case o = <-doneC:
+				case <-timer.C:
+					key, _ := JobKey(ctx)
+					loggerFromContext(ctx).Error("job run abandoned after timeout", "key", key, "timeout", timeout, "grace", grace)
+					if timedOut() {
+						return &TimeoutError{Timeout: timeout, Err: ctxTimeout.Err()}
+					}
+					return ctx.Err()
+				}
+			}
+			if o.panicked {
+				panic(o.value)
+			}
+			return result(o.err)
+		})
+	}
+}
@@ -0,0 +1,104 @@
+package cron
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestWrapJobTimeout(t *testing.T) {
+	errJob := errors.New("job failed")
+	for _, grace := range []time.Duration{0, time.Second} {
+		wrapper := WrapJobTimeout(time.Millisecond*20, grace)
+
+		// The job returns after the context is canceled by timeout.
+		start := time.Now()
+		err := wrapper(JobFunc(func(ctx context.Context) error {
+			<-ctx.Done()
+			return ctx.Err()
+		})).Run(context.Background())
+		require.True(t, errors.Is(err, ErrJobTimeout))
+		require.True(t, errors.Is(err, context.DeadlineExceeded))
+		require.True(t, time.Since(start) < time.Millisecond*500)
+
+		// The error of job is kept.
+		err = wrapper(JobFunc(func(ctx context.Context) error {
+			<-ctx.Done()
+			return errJob
+		})).Run(context.Background())
+		var timeoutErr *TimeoutError
+		require.True(t, errors.As(err, &timeoutErr))
+		require.Equal(t, time.Millisecond*20, timeoutErr.Timeout)
+		require.True(t, errors.Is(err, ErrJobTimeout))
+		require.True(t, errors.Is(err, errJob))
+		require.Equal(t, "cron: job run timeout after 20ms: job failed", err.Error())
+
+		// The job completed before timeout.
+		err = wrapper(JobFunc(func(ctx context.Context) error {
+			return errJob
+		})).Run(context.Background())
+		require.Equal(t, errJob, err)
+
+		// The parent context is canceled.
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		err = wrapper(JobFunc(func(ctx context.Context) error {
+			<-ctx.Done()
+			return ctx.Err()
+		})).Run(ctx)
+		require.Equal(t, context.Canceled, err)
+	}
+
+	require.Panics(t, func() { WrapJobTimeout(0, 0) })
+}
+
+func TestWrapJobTimeout_Abandon(t *testing.T) {
+	releaseC := make(chan struct{})
+	defer close(releaseC)
+	var returned int32
+	job := WrapJobTimeout(time.Millisecond*20, time.Millisecond*20)(JobFunc(func(ctx context.Context) error {
+		// Ignores the context.
+		<-releaseC
+		atomic.StoreInt32(&returned, 1)
+		return nil
+	}))
+
+	start := time.Now()
+	err := job.Run(context.Background())
+	require.True(t, errors.Is(err, ErrJobTimeout))
+	require.True(t, errors.Is(err, context.DeadlineExceeded))
+	require.True(t, time.Since(start) >= time.Millisecond*40)
+	require.Equal(t, int32(0), atomic.LoadInt32(&returned))
+}
+
+func TestWrapJobTimeout_Panic(t *testing.T) {
+	job := JobChain{WrapJobRecover(), WrapJobTimeout(time.Second, time.Second)}.Apply(JobFunc(func(ctx context.Context) error {
+		panic("oops")
+	}))
+	err := job.Run(context.Background())
+	var panicErr *PanicError
+	require.True(t, errors.As(err, &panicErr))
+	require.Equal(t, "oops", panicErr.Value)
+}
+
+func TestWrapJobTimeout_Retry(t *testing.T) {
+	var attempts int32
+	job := JobChain{
+		WrapJobRetry(context.Background(), 2, time.Millisecond*10),
+		WrapJobTimeout(time.Millisecond*20, 0),
+	}.Apply(JobFunc(func(ctx context.Context) error {
+		// Succeeds at the last attempt.
+		if atomic.AddInt32(&attempts, 1) == 3 {
+			return nil
+		}
+		<-ctx.Done()
+		return ctx.Err()
+	}))
+
+	require.Nil(t, job.Run(context.Background()))
+	require.Equal(t, int32(3), atomic.LoadInt32(&attempts))
+}